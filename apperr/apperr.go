@@ -0,0 +1,102 @@
+// Package apperr defines the errors services return for expected failures.
+// Each carries a Kind that the HTTP layer maps to a status code and a Detail
+// that is safe to show to clients. Any other error is treated as internal
+// and its text is never sent to clients.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindValidation
+	KindConflict
+	KindUnauthorized
+	KindForbidden
+	// KindUnavailable means a dependency needed to answer is down.
+	KindUnavailable
+	// KindPreconditionFailed means a conditional request's precondition,
+	// such as If-Match, did not hold.
+	KindPreconditionFailed
+	// KindPreconditionRequired means a write was sent without the
+	// precondition the endpoint requires.
+	KindPreconditionRequired
+	// KindUnsupportedMediaType means the request body is in a format the
+	// endpoint does not accept.
+	KindUnsupportedMediaType
+)
+
+// FieldError describes one invalid input field.
+type FieldError struct {
+	Field   string `json:"field" example:"price"`
+	Message string `json:"message" example:"must be greater than 0"`
+}
+
+type Error struct {
+	Kind Kind
+	// Detail is a client-safe explanation of this occurrence.
+	Detail string
+	// Fields lists invalid inputs for KindValidation errors.
+	Fields []FieldError
+	// Err is the underlying cause, kept for logs and errors.Is.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Detail, e.Err)
+	}
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(detail string) *Error {
+	return &Error{Kind: KindNotFound, Detail: detail}
+}
+
+func Validation(detail string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Detail: detail, Fields: fields}
+}
+
+func Conflict(detail string) *Error {
+	return &Error{Kind: KindConflict, Detail: detail}
+}
+
+func Unauthorized(detail string) *Error {
+	return &Error{Kind: KindUnauthorized, Detail: detail}
+}
+
+func Forbidden(detail string) *Error {
+	return &Error{Kind: KindForbidden, Detail: detail}
+}
+
+func Unavailable(detail string, err error) *Error {
+	return &Error{Kind: KindUnavailable, Detail: detail, Err: err}
+}
+
+func PreconditionFailed(detail string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Detail: detail}
+}
+
+func PreconditionRequired(detail string) *Error {
+	return &Error{Kind: KindPreconditionRequired, Detail: detail}
+}
+
+func UnsupportedMediaType(detail string) *Error {
+	return &Error{Kind: KindUnsupportedMediaType, Detail: detail}
+}
+
+// As returns the *Error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCacheMiss is returned by Get when the key is absent or expired.
+var ErrCacheMiss = errors.New("cache miss")
+
+const (
+	REDIS  = "redis"
+	MEMORY = "memory"
+	NONE   = "none"
+)
+
+// Cache is a key/value store with per-key expiry. Implementations must be
+// safe for concurrent use.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value only if key is absent and reports whether it did.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Noop never stores anything; every Get is a miss.
+type Noop struct{}
+
+func NewNoop() Cache {
+	return Noop{}
+}
+
+func (Noop) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, ErrCacheMiss
+}
+
+func (Noop) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+func (Noop) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (Noop) Exists(ctx context.Context, key string) (bool, error) {
+	return false, nil
+}
+
+func (Noop) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
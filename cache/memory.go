@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process LRU cache. It is local to one replica and lost on
+// restart, so it suits single-instance deployments and development.
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	lastSweep  time.Time
+}
+
+// sweepInterval bounds how often Set scans for expired entries that were
+// never read again.
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemory returns an LRU cache holding at most maxEntries keys, evicting
+// the least recently used first. maxEntries <= 0 means no limit.
+func NewMemory(maxEntries int) Cache {
+	return &Memory{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.lookup(key)
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	m.ll.MoveToFront(el)
+	return el.Value.(*memoryEntry).value, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.store(key, value, ttl)
+	return nil
+}
+
+func (m *Memory) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.lookup(key); ok {
+		return false, nil
+	}
+	m.store(key, value, ttl)
+	return true, nil
+}
+
+func (m *Memory) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.lookup(key)
+	return ok, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := m.items[key]; ok {
+			m.remove(el)
+		}
+	}
+	return nil
+}
+
+// lookup returns the live element for key, dropping it if it has expired.
+func (m *Memory) lookup(key string) (*list.Element, bool) {
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		m.remove(el)
+		return nil, false
+	}
+	return el, true
+}
+
+func (m *Memory) store(key string, value []byte, ttl time.Duration) {
+	if time.Since(m.lastSweep) > sweepInterval {
+		m.sweep()
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if el, ok := m.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.ll.MoveToFront(el)
+		return
+	}
+
+	m.items[key] = m.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	if m.maxEntries > 0 && m.ll.Len() > m.maxEntries {
+		m.remove(m.ll.Back())
+	}
+}
+
+func (m *Memory) sweep() {
+	now := time.Now()
+	for el := m.ll.Back(); el != nil; {
+		prev := el.Prev()
+		entry := el.Value.(*memoryEntry)
+		if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			m.remove(el)
+		}
+		el = prev
+	}
+	m.lastSweep = now
+}
+
+func (m *Memory) remove(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,22 @@
+package main
+
+import "os"
+
+const configUsage = `usage: app config print [flags]
+
+Prints the effective config as YAML, with secrets redacted.`
+
+// runConfig handles the config subcommand.
+func runConfig(args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		usageError(configUsage)
+	}
+
+	cfg, _, rest := loadConfig(configUsage, args[1:])
+	if len(rest) > 0 {
+		usageError(configUsage)
+	}
+	if err := cfg.WriteYAML(os.Stdout); err != nil {
+		fatal("failed to print config", err)
+	}
+}
@@ -1,21 +1,150 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"example/go-web-gin/config"
+	"example/go-web-gin/database"
+	"example/go-web-gin/logging"
 	"example/go-web-gin/middleware"
 	"example/go-web-gin/router"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 )
 
+// @title Album API
+// @version 1.0
+// @description REST API for managing record albums.
+// @BasePath /api/v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the access token.
 func main() {
-	config.LoadConfig()
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			runMigrate(args[1:])
+			return
+		case "config":
+			runConfig(args[1:])
+			return
+		}
+	}
 
-	route := gin.Default()
-	// Use reusable request logging middleware
-	route.Use(middleware.RequestLogger())
+	cfg, logger, rest := loadConfig(serveUsage, args)
+	if len(rest) > 0 {
+		usageError(serveUsage)
+	}
+	logger.Info("config loaded", "config", cfg)
+
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid config", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// in docker-compose the database may still be starting, so this retries
+	// with backoff and can be interrupted like the server itself
+	if err := database.ConnectDB(ctx); err != nil {
+		fatal("failed to connect to database", err)
+	}
+
+	route := gin.New()
+	// tag each request with an ID before anything logs it
+	route.Use(middleware.RequestID())
+	route.Use(middleware.RequestLogger(logger))
+	// render errors from everything below as problem+json
+	route.Use(middleware.ErrorHandler())
+	route.Use(middleware.Recovery(logger))
+	// Bound how long each request may spend in the database and cache
+	route.Use(middleware.Timeout(cfg.HTTP.RequestTimeout, cfg.HTTP.RouteTimeouts))
+	route.Use(middleware.CacheControl(cfg.HTTP.CacheControl))
 	// Register routes from router package
-	router.RegisterRoutes(route)
+	if err := router.RegisterRoutes(route, logger); err != nil {
+		fatal("failed to register routes", err)
+	}
+
+	srv := &http.Server{
+		Addr:         ":" + cfg.HTTP.Port,
+		Handler:      route,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+	}
+
+	go func() {
+		logger.Info("server listening", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("server failed", err)
+		}
+	}()
+
+	<-ctx.Done()
+	// a second signal kills the process immediately
+	stop()
+	logger.Info("shutting down, draining in-flight requests")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("server did not shut down cleanly", "error", err)
+	}
+
+	// close backing stores only after the last request has finished
+	database.CloseDB()
+	config.CloseRedis()
+	logger.Info("server stopped")
+}
+
+const serveUsage = `usage: app [flags]
+       app migrate [flags] <command>
+       app config print [flags]
+
+Runs the HTTP server. Settings come from defaults, then the -config file,
+then environment variables, then flags, each overriding the one before.`
+
+// loadConfig resolves the config with the flags in args, installs the
+// default logger and returns the arguments left after the flags.
+func loadConfig(usage string, args []string) (*config.Config, *slog.Logger, []string) {
+	fs := flag.NewFlagSet("app", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fmt.Fprintln(fs.Output(), "\nflags:")
+		fs.PrintDefaults()
+	}
+	if err := config.LoadConfig(fs, args); err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		os.Exit(2)
+	}
+	cfg := config.AppConfig
+
+	logger, err := logging.New(os.Stderr, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		os.Exit(2)
+	}
+	// packages without an injected logger use the default one
+	slog.SetDefault(logger)
+	return cfg, logger, fs.Args()
+}
+
+func usageError(usage string) {
+	fmt.Fprintln(os.Stderr, usage)
+	os.Exit(2)
+}
 
-	route.Run(":8080")
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
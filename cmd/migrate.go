@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"example/go-web-gin/database"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+const migrateUsage = `usage: app migrate [flags] <command>
+
+commands:
+  up              apply all pending migrations
+  down [N|all]    roll back N migrations (default 1), or all of them
+  status          show the current and latest migration version
+  force VERSION   set the version without running migrations (to clear a dirty state)`
+
+// runMigrate handles the migrate subcommand against the configured database.
+func runMigrate(args []string) {
+	cfg, _, args := loadConfig(migrateUsage, args)
+	if len(args) == 0 {
+		usageError(migrateUsage)
+	}
+
+	db, err := database.Open(cfg.DB)
+	if err == nil {
+		err = database.PingWithRetry(context.Background(), db, cfg.DB.ConnectRetries, cfg.DB.ConnectBackoff)
+	}
+	if err != nil {
+		fatal("failed to connect to database", err)
+	}
+	m, err := database.NewMigrator(db, cfg.DB.Driver)
+	if err != nil {
+		fatal("failed to load migrations", err)
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+
+	case "down":
+		switch {
+		case len(args) < 2:
+			err = m.Steps(-1)
+		case args[1] == "all":
+			err = m.Down()
+		default:
+			n, convErr := strconv.Atoi(args[1])
+			if convErr != nil || n < 1 {
+				usageError(migrateUsage)
+			}
+			err = m.Steps(-n)
+		}
+
+	case "status":
+		err = printMigrationStatus(m, cfg.DB.Driver)
+
+	case "force":
+		if len(args) < 2 {
+			usageError(migrateUsage)
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			usageError(migrateUsage)
+		}
+		err = m.Force(version)
+
+	default:
+		usageError(migrateUsage)
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		slog.Info("no migrations to apply")
+		err = nil
+	}
+	if err != nil {
+		m.Close()
+		fatal("migration failed", err)
+	}
+
+	if args[0] != "status" {
+		printMigrationStatus(m, cfg.DB.Driver)
+	}
+}
+
+func printMigrationStatus(m *migrate.Migrate, driver string) error {
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	latest, err := database.LatestMigration(driver)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("driver:  %s\nversion: %d\nlatest:  %d\ndirty:   %t\n", driver, version, latest, dirty)
+	return nil
+}
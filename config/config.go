@@ -1,50 +1,269 @@
+// Package config loads the application settings. Each setting is resolved
+// from the following sources, later ones overriding earlier ones:
+//
+//  1. built-in defaults
+//  2. the YAML file named by -config or CONFIG_FILE
+//  3. environment variables, including those loaded from .env
+//  4. command-line flags
+//
+// Secrets may also be read from a file named by the variable's _FILE form,
+// e.g. DB_PASSWORD_FILE. See config.example.yaml for every setting.
 package config
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"reflect"
+	"time"
 
 	"github.com/joho/godotenv"
+	"go.yaml.in/yaml/v3"
 )
 
 type Config struct {
-	DBDRIVER   string
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	JWTSecret  string
-	RedisAddr  string
+	DB    DBConfig    `yaml:"db"`
+	Redis RedisConfig `yaml:"redis"`
+	JWT   JWTConfig   `yaml:"jwt"`
+	HTTP  HTTPConfig  `yaml:"http"`
+	Cache CacheConfig `yaml:"cache"`
+	Log   LogConfig   `yaml:"log"`
+}
+
+type DBConfig struct {
+	// Driver is one of "postgres", "mysql" or "sqlite".
+	Driver   string `yaml:"driver"`
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password Secret `yaml:"password"`
+	Name     string `yaml:"name"`
+	// Path is the database file used by the sqlite driver.
+	Path string `yaml:"path"`
+	// AutoMigrate applies pending migrations on boot. SQLite is always migrated.
+	AutoMigrate bool `yaml:"auto_migrate"`
+
+	// Connection pool limits. The sqlite driver always uses one connection.
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	// ConnectRetries is how many times a failed ping is retried at boot,
+	// starting ConnectBackoff apart and doubling each time.
+	ConnectRetries int           `yaml:"connect_retries"`
+	ConnectBackoff time.Duration `yaml:"connect_backoff"`
+}
+
+type RedisConfig struct {
+	Addr     string `yaml:"addr"`
+	Password Secret `yaml:"password"`
+	// DB is the Redis logical database index.
+	DB  int  `yaml:"db"`
+	TLS bool `yaml:"tls"`
+}
+
+type JWTConfig struct {
+	Secret          Secret        `yaml:"secret"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+}
+
+type HTTPConfig struct {
+	Port         string        `yaml:"port"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout is how long in-flight requests get to finish on SIGTERM.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// HealthCheckTimeout bounds each dependency ping in /readyz.
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout"`
+	// RequestTimeout is the deadline put on each request's context.
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// RouteTimeouts overrides RequestTimeout per route, keyed by
+	// "METHOD /full/path" as registered, e.g. "GET /api/v1/albums/search".
+	RouteTimeouts map[string]time.Duration `yaml:"route_timeouts"`
+	// CacheControl sets the Cache-Control header of successful responses
+	// per route, keyed like RouteTimeouts.
+	CacheControl map[string]string `yaml:"cache_control"`
+}
+
+type CacheConfig struct {
+	// Driver is one of "redis", "memory" or "none". Auth token state is
+	// kept in Redis with the redis driver and in process memory otherwise.
+	Driver string `yaml:"driver"`
+	// AllowMemoryTokens lets the redis driver start when Redis is
+	// unreachable, with albums and auth token state in process memory.
+	// Revocations then only reach this replica and are lost on restart.
+	AllowMemoryTokens bool `yaml:"allow_memory_tokens"`
+	// Size caps the number of albums held by the memory cache.
+	Size int `yaml:"size"`
+	// TTL is how long an album stays cached.
+	TTL time.Duration `yaml:"ttl"`
+}
+
+type LogConfig struct {
+	// Level is one of "debug", "info", "warn" or "error".
+	Level string `yaml:"level"`
+	// Format is "json" or "text".
+	Format string `yaml:"format"`
 }
 
 var AppConfig *Config
 
-func LoadConfig() {
+// Default returns the settings used when no source overrides them.
+func Default() *Config {
+	return &Config{
+		DB: DBConfig{
+			Driver:          "postgres",
+			Path:            "albums.db",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectRetries:  8,
+			ConnectBackoff:  500 * time.Millisecond,
+		},
+		JWT: JWTConfig{
+			AccessTokenTTL:  1 * time.Hour,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		HTTP: HTTPConfig{
+			Port:               "8080",
+			ReadTimeout:        15 * time.Second,
+			WriteTimeout:       15 * time.Second,
+			IdleTimeout:        60 * time.Second,
+			ShutdownTimeout:    30 * time.Second,
+			HealthCheckTimeout: 2 * time.Second,
+			RequestTimeout:     10 * time.Second,
+			RouteTimeouts:      map[string]time.Duration{},
+			// shared caches may store album reads but must revalidate
+			// them, which the ETags make cheap
+			CacheControl: map[string]string{
+				"GET /api/v1/albums/":    "no-cache",
+				"GET /api/v1/albums/:id": "no-cache",
+			},
+		},
+		Cache: CacheConfig{
+			Driver: "redis",
+			Size:   10000,
+			TTL:    60 * time.Minute,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+}
+
+// LoadConfig registers a flag for every setting on fs, parses args and
+// sets AppConfig from all sources. Positional arguments are left in fs.Args().
+func LoadConfig(fs *flag.FlagSet, args []string) error {
+	cfg := Default()
+	settings := cfg.settings()
+
+	configFile := fs.String("config", "", "path to a YAML config file (env CONFIG_FILE)")
+	flags := registerFlags(fs, settings)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
 	// Try to load .env but don't fail if it's missing — allow real env vars.
-	if err := godotenv.Load(); err != nil {
-		log.Println(".env file not found; falling back to environment variables")
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("load .env: %w", err)
+	}
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
 	}
+	if path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return err
+		}
+	}
+
+	if err := applyEnv(settings); err != nil {
+		return err
+	}
+	if err := flags.apply(); err != nil {
+		return err
+	}
+
+	AppConfig = cfg
+	return nil
+}
 
-	// read values from environment (possibly set by .env)
-	driver := os.Getenv("DB_DRIVER")
-	if driver == "" {
-		driver = "postgres" // sensible default
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
 	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	// a misspelt key would otherwise be silently ignored
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// MinJWTSecretLength is the shortest JWT secret accepted. HS256 keys should
+// be at least as long as the 256-bit hash.
+const MinJWTSecretLength = 32
 
-	AppConfig = &Config{
-		DBDRIVER:   driver,
-		DBHost:     os.Getenv("DB_HOST"),
-		DBUser:     os.Getenv("DB_USER"),
-		DBPassword: os.Getenv("DB_PASSWORD"),
-		DBName:     os.Getenv("DB_NAME"),
-		DBPort:     os.Getenv("DB_PORT"),
-		JWTSecret:  os.Getenv("JWT_SECRET"),
-		RedisAddr:  os.Getenv("REDISADDR"),
+// Validate reports settings the server cannot run with.
+func (c *Config) Validate() error {
+	switch n := len(c.JWT.Secret); {
+	case n == 0:
+		return errors.New("JWT_SECRET is required")
+	case n < MinJWTSecretLength:
+		return fmt.Errorf("JWT_SECRET must be at least %d characters, got %d", MinJWTSecretLength, n)
 	}
+	switch c.Cache.Driver {
+	case "redis", "memory", "none":
+	default:
+		return fmt.Errorf("CACHE_DRIVER must be redis, memory or none, got %q", c.Cache.Driver)
+	}
+	return nil
+}
 
-	fmt.Println(AppConfig)
+// String prints every setting with secrets redacted.
+func (c *Config) String() string {
+	// plain drops the String method so Sprintf does not recurse
+	type plain Config
+	return fmt.Sprintf("%+v", plain(*c))
+}
+
+// LogValue logs every setting with secrets redacted.
+func (c *Config) LogValue() slog.Value {
+	return structLogValue(reflect.ValueOf(*c))
+}
 
-	log.Println("Config loaded successfully")
+func structLogValue(v reflect.Value) slog.Value {
+	attrs := make([]slog.Attr, 0, v.NumField())
+	for i := range v.NumField() {
+		name := v.Type().Field(i).Tag.Get("yaml")
+		switch value := v.Field(i).Interface().(type) {
+		case time.Duration:
+			// "15s" rather than nanoseconds
+			attrs = append(attrs, slog.String(name, value.String()))
+		case map[string]time.Duration:
+			routes := make(map[string]string, len(value))
+			for route, d := range value {
+				routes[route] = d.String()
+			}
+			attrs = append(attrs, slog.Any(name, routes))
+		default:
+			if v.Field(i).Kind() == reflect.Struct {
+				attrs = append(attrs, slog.Attr{Key: name, Value: structLogValue(v.Field(i))})
+				continue
+			}
+			attrs = append(attrs, slog.Any(name, value))
+		}
+	}
+	return slog.GroupValue(attrs...)
 }
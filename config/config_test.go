@@ -0,0 +1,23 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCacheDriver(t *testing.T) {
+	for _, driver := range []string{"redis", "memory", "none", "", "memcached"} {
+		cfg := Default()
+		cfg.JWT.Secret = Secret(strings.Repeat("x", MinJWTSecretLength))
+		cfg.Cache.Driver = driver
+
+		err := cfg.Validate()
+		valid := driver == "redis" || driver == "memory" || driver == "none"
+		if valid && err != nil {
+			t.Errorf("driver %q: unexpected error %v", driver, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("driver %q: accepted, want an error", driver)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// WriteYAML writes the config in the config file format, with secrets
+// redacted and durations written as "15s" rather than nanoseconds.
+func (c *Config) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(reflect.ValueOf(*c))); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func yamlNode(v reflect.Value) *yaml.Node {
+	switch value := v.Interface().(type) {
+	case Secret:
+		return scalarNode(value.String())
+	case time.Duration:
+		return scalarNode(value.String())
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := range v.NumField() {
+			node.Content = append(node.Content,
+				scalarNode(v.Type().Field(i).Tag.Get("yaml")),
+				yamlNode(v.Field(i)),
+			)
+		}
+		return node
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			node.Content = append(node.Content, scalarNode(k.String()), yamlNode(v.MapIndex(k)))
+		}
+		return node
+	default:
+		node := &yaml.Node{}
+		node.Encode(v.Interface())
+		return node
+	}
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -2,26 +2,53 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-var (
-	Ctx         = context.Background()
-	RedisClient *redis.Client
-)
+var RedisClient *redis.Client
+
+const redisConnectTimeout = 5 * time.Second
+
+// ConnectRedis opens RedisClient and pings it. On failure RedisClient is left
+// nil so callers can fall back to another cache.
+func ConnectRedis() error {
+	cfg := AppConfig.Redis
+	opts := &redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password.Reveal(),
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		host, _, _ := net.SplitHostPort(cfg.Addr)
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: host}
+	}
+	client := redis.NewClient(opts)
 
-func ExampleClient() {
-	RedisClient = redis.NewClient(&redis.Options{
-		Addr:     AppConfig.RedisAddr,
-		Password: "", // no password set
-		DB:       0,  // use default DB
-	})
-
-	if err := RedisClient.Ping(Ctx).Err(); err != nil {
-		panic("❌ Failed to connect to Redis")
-	} else {
-		println("✅ Connected to Redis successfully")
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return err
 	}
 
+	RedisClient = client
+	slog.Info("connected to redis", "addr", cfg.Addr)
+	return nil
+}
+
+// CloseRedis closes RedisClient if it was opened.
+func CloseRedis() {
+	if RedisClient == nil {
+		return
+	}
+	if err := RedisClient.Close(); err != nil {
+		slog.Error("failed to close redis client", "error", err)
+		return
+	}
+	slog.Info("redis connection closed")
 }
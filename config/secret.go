@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Secret is a string that redacts itself when printed, logged or marshalled.
+// Use Reveal where the real value is needed.
+type Secret string
+
+const redacted = "[REDACTED]"
+
+// Reveal returns the secret value.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// String returns a placeholder, or "" when the secret is not set so that an
+// empty value still shows up as missing.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
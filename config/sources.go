@@ -0,0 +1,228 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting binds one field of Config to its environment variables and flag.
+type setting struct {
+	// flag is the command-line flag name, which mirrors the YAML path
+	flag string
+	// env lists the variables read, the first one set winning; later
+	// entries are kept for backwards compatibility
+	env   []string
+	usage string
+	ptr   any
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"db.driver", []string{"DB_DRIVER"}, "database driver: postgres, mysql or sqlite", &c.DB.Driver},
+		{"db.host", []string{"DB_HOST"}, "database host", &c.DB.Host},
+		{"db.port", []string{"DB_PORT"}, "database port", &c.DB.Port},
+		{"db.user", []string{"DB_USER"}, "database user", &c.DB.User},
+		{"db.password", []string{"DB_PASSWORD"}, "database password", &c.DB.Password},
+		{"db.name", []string{"DB_NAME"}, "database name", &c.DB.Name},
+		{"db.path", []string{"DB_PATH"}, "database file for the sqlite driver, or :memory:", &c.DB.Path},
+		{"db.auto_migrate", []string{"AUTO_MIGRATE"}, "apply pending migrations on boot", &c.DB.AutoMigrate},
+		{"db.max_open_conns", []string{"DB_MAX_OPEN_CONNS"}, "maximum open connections", &c.DB.MaxOpenConns},
+		{"db.max_idle_conns", []string{"DB_MAX_IDLE_CONNS"}, "maximum idle connections", &c.DB.MaxIdleConns},
+		{"db.conn_max_lifetime", []string{"DB_CONN_MAX_LIFETIME"}, "maximum time a connection is reused", &c.DB.ConnMaxLifetime},
+		{"db.conn_max_idle_time", []string{"DB_CONN_MAX_IDLE_TIME"}, "maximum time a connection stays idle", &c.DB.ConnMaxIdleTime},
+		{"db.connect_retries", []string{"DB_CONNECT_RETRIES"}, "times a failed ping is retried at boot", &c.DB.ConnectRetries},
+		{"db.connect_backoff", []string{"DB_CONNECT_BACKOFF"}, "initial wait between connection attempts", &c.DB.ConnectBackoff},
+
+		{"redis.addr", []string{"REDIS_ADDR", "REDISADDR"}, "Redis host:port", &c.Redis.Addr},
+		{"redis.password", []string{"REDIS_PASSWORD"}, "Redis password", &c.Redis.Password},
+		{"redis.db", []string{"REDIS_DB"}, "Redis database index", &c.Redis.DB},
+		{"redis.tls", []string{"REDIS_TLS"}, "connect to Redis over TLS", &c.Redis.TLS},
+
+		{"jwt.secret", []string{"JWT_SECRET"}, "HMAC key for access tokens, at least 32 characters", &c.JWT.Secret},
+		{"jwt.access_token_ttl", []string{"JWT_ACCESS_TOKEN_TTL"}, "access token lifetime", &c.JWT.AccessTokenTTL},
+		{"jwt.refresh_token_ttl", []string{"JWT_REFRESH_TOKEN_TTL"}, "refresh token lifetime", &c.JWT.RefreshTokenTTL},
+
+		{"http.port", []string{"HTTP_PORT"}, "port to listen on", &c.HTTP.Port},
+		{"http.read_timeout", []string{"HTTP_READ_TIMEOUT"}, "maximum time to read a request", &c.HTTP.ReadTimeout},
+		{"http.write_timeout", []string{"HTTP_WRITE_TIMEOUT"}, "maximum time to write a response", &c.HTTP.WriteTimeout},
+		{"http.idle_timeout", []string{"HTTP_IDLE_TIMEOUT"}, "keep-alive idle timeout", &c.HTTP.IdleTimeout},
+		{"http.shutdown_timeout", []string{"SHUTDOWN_TIMEOUT"}, "time in-flight requests get to finish on SIGTERM", &c.HTTP.ShutdownTimeout},
+		{"http.health_check_timeout", []string{"HEALTH_CHECK_TIMEOUT"}, "timeout for each dependency ping in /readyz", &c.HTTP.HealthCheckTimeout},
+		{"http.request_timeout", []string{"REQUEST_TIMEOUT"}, "deadline for each request", &c.HTTP.RequestTimeout},
+		{"http.route_timeouts", []string{"ROUTE_TIMEOUTS"}, `per-route deadlines, e.g. "GET /api/v1/albums/search=3s,POST /api/v1/auth/login=5s"`, &c.HTTP.RouteTimeouts},
+		{"http.cache_control", []string{"CACHE_CONTROL"}, `per-route Cache-Control headers separated by ";", e.g. "GET /api/v1/albums/:id=public, max-age=60"`, &c.HTTP.CacheControl},
+
+		{"cache.driver", []string{"CACHE_DRIVER"}, "album cache and auth token store: redis, memory or none", &c.Cache.Driver},
+		{"cache.allow_memory_tokens", []string{"CACHE_ALLOW_MEMORY_TOKENS"}, "keep auth token state in process memory when Redis is unreachable at boot, instead of failing; only safe with one replica", &c.Cache.AllowMemoryTokens},
+		{"cache.size", []string{"CACHE_SIZE"}, "maximum albums held by the memory cache", &c.Cache.Size},
+		{"cache.ttl", []string{"CACHE_TTL"}, "how long an album stays cached", &c.Cache.TTL},
+
+		{"log.level", []string{"LOG_LEVEL"}, "log level: debug, info, warn or error", &c.Log.Level},
+		{"log.format", []string{"LOG_FORMAT"}, "log format: json or text", &c.Log.Format},
+	}
+}
+
+// applyEnv overrides settings from the environment. A secret may instead be
+// read from the file named by its variable's _FILE form, as mounted by
+// Docker and Kubernetes secrets.
+func applyEnv(settings []setting) error {
+	for _, s := range settings {
+		for _, key := range s.env {
+			raw, ok, err := lookupEnv(key, s.ptr)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := setValue(s.ptr, raw); err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func lookupEnv(key string, ptr any) (string, bool, error) {
+	value := os.Getenv(key)
+	if _, isSecret := ptr.(*Secret); !isSecret {
+		return value, value != "", nil
+	}
+
+	fileKey := key + "_FILE"
+	path := os.Getenv(fileKey)
+	if path == "" {
+		return value, value != "", nil
+	}
+	if value != "" {
+		return "", false, fmt.Errorf("both %s and %s are set; use only one", key, fileKey)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("read %s: %w", fileKey, err)
+	}
+	// secret files usually end with a newline
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// flagValues collects flags as they are parsed so they can be applied after
+// the file and environment.
+type flagValues struct {
+	set []flagValue
+}
+
+type flagValue struct {
+	setting setting
+	raw     string
+}
+
+func registerFlags(fs *flag.FlagSet, settings []setting) *flagValues {
+	values := &flagValues{}
+	for _, s := range settings {
+		usage := s.usage
+		if len(s.env) > 0 {
+			usage += " (env " + s.env[0] + ")"
+		}
+		record := func(raw string) error {
+			values.set = append(values.set, flagValue{setting: s, raw: raw})
+			return nil
+		}
+		if _, isBool := s.ptr.(*bool); isBool {
+			// allows -db.auto_migrate as well as -db.auto_migrate=false
+			fs.BoolFunc(s.flag, usage, record)
+			continue
+		}
+		fs.Func(s.flag, usage, record)
+	}
+	return values
+}
+
+func (v *flagValues) apply() error {
+	for _, f := range v.set {
+		if err := setValue(f.setting.ptr, f.raw); err != nil {
+			return fmt.Errorf("invalid -%s: %w", f.setting.flag, err)
+		}
+	}
+	return nil
+}
+
+func setValue(ptr any, raw string) error {
+	switch p := ptr.(type) {
+	case *string:
+		*p = raw
+	case *Secret:
+		*p = Secret(raw)
+	case *int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		*p = n
+	case *bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		*p = b
+	case *time.Duration:
+		// parses values like "30s" or "1m"
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration", raw)
+		}
+		*p = d
+	case *map[string]time.Duration:
+		timeouts, err := parseRouteTimeouts(raw)
+		if err != nil {
+			return err
+		}
+		*p = timeouts
+	case *map[string]string:
+		headers, err := parseCacheControl(raw)
+		if err != nil {
+			return err
+		}
+		*p = headers
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %T", ptr))
+	}
+	return nil
+}
+
+// parseRouteTimeouts parses a comma-separated list of route=duration pairs,
+// e.g. "GET /api/v1/albums/search=3s,POST /api/v1/auth/login=5s".
+func parseRouteTimeouts(raw string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, pair := range strings.Split(raw, ",") {
+		route, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q is not route=duration", pair)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("entry %q has an invalid duration", pair)
+		}
+		timeouts[strings.TrimSpace(route)] = d
+	}
+	return timeouts, nil
+}
+
+// parseCacheControl parses a semicolon-separated list of route=header pairs,
+// e.g. "GET /api/v1/albums/:id=public, max-age=60;GET /api/v1/albums/=no-cache".
+// Semicolons separate entries because the headers themselves contain commas.
+func parseCacheControl(raw string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(raw, ";") {
+		route, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("entry %q is not route=header", pair)
+		}
+		headers[strings.TrimSpace(route)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
@@ -1,49 +1,122 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"example/go-web-gin/config"
 	"fmt"
-	"log"
+	"log/slog"
 
+	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
-var DB *sql.DB
+var (
+	DB *sql.DB
+	// SQLDialect matches the driver DB was opened with.
+	SQLDialect Dialect
+)
 
 const (
 	POSTGRES = "postgres"
 	MYSQL    = "mysql"
+	SQLITE   = "sqlite"
 )
 
-func ConnectDB() {
-	cfg := config.AppConfig
+// ConnectDB opens the pool, waits for the database to accept connections
+// and brings the schema up to date. Cancelling ctx stops the wait.
+func ConnectDB(ctx context.Context) error {
+	cfg := config.AppConfig.DB
+
+	db, err := Open(cfg)
+	if err != nil {
+		return err
+	}
+	if err := PingWithRetry(ctx, db, cfg.ConnectRetries, cfg.ConnectBackoff); err != nil {
+		db.Close()
+		return err
+	}
+
+	switch {
+	case cfg.Driver == SQLITE:
+		// a SQLite database is usually fresh (a new file or :memory:), so
+		// it is always brought up to date, on this pool since :memory:
+		// is only visible to the connection that created it
+		err = migrateSQLite(db)
+	case cfg.AutoMigrate:
+		err = AutoMigrate(cfg)
+	}
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("migrate database: %w", err)
+	}
 
-	var db *sql.DB
-	var err error
+	DB = db
+	SQLDialect = DialectFor(cfg.Driver)
+	slog.Info("database connection established", "driver", cfg.Driver)
+	return nil
+}
 
-	switch cfg.DBDRIVER {
+// Open opens a connection pool for the configured driver.
+func Open(cfg config.DBConfig) (*sql.DB, error) {
+	switch cfg.Driver {
 	case POSTGRES:
+		// timestamp columns have no zone, so CURRENT_TIMESTAMP must be
+		// taken in UTC whatever the server's default timezone is
 		dsn := fmt.Sprintf(
-			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-			cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort,
+			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable timezone=UTC",
+			cfg.Host, cfg.User, cfg.Password.Reveal(), cfg.Name, cfg.Port,
 		)
-		db, err = sql.Open(POSTGRES, dsn)
+		return openPool(POSTGRES, dsn, cfg)
 
 	case MYSQL:
+		// clientFoundRows makes RowsAffected count matched rows, so an
+		// UPDATE that changes nothing is not mistaken for a missing row.
+		// MySQL converts TIMESTAMP values to the session time_zone, which
+		// is pinned to UTC to match loc and the other dialects.
 		dsn := fmt.Sprintf(
-			"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName,
+			"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC&time_zone=%%27%%2B00%%3A00%%27&clientFoundRows=true",
+			cfg.User, cfg.Password.Reveal(), cfg.Host, cfg.Port, cfg.Name,
 		)
-		db, err = sql.Open(MYSQL, dsn)
+		return openPool(MYSQL, dsn, cfg)
+
+	case SQLITE:
+		// DB_PATH is a file path, or ":memory:" for a throwaway database.
+		// go-sqlite3 uses cgo, so building the app needs a C compiler.
+		dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000", cfg.Path)
+		db, err := sql.Open("sqlite3", dsn)
+		if err != nil {
+			return nil, err
+		}
+		// SQLite allows one writer at a time, and each connection to
+		// :memory: would otherwise get its own empty database. The pool
+		// settings are skipped so that connection is never recycled.
+		db.SetMaxOpenConns(1)
+		return db, nil
+
 	default:
-		log.Fatal("Unsupported DB driver:", cfg.DBDRIVER)
+		return nil, fmt.Errorf("unsupported DB driver: %q", cfg.Driver)
 	}
+}
+
+func openPool(driver, dsn string, cfg config.DBConfig) (*sql.DB, error) {
+	db, err := sql.Open(driver, dsn)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return nil, err
 	}
+	configurePool(db, cfg)
+	return db, nil
+}
 
-	DB = db
-	log.Println("Database connection established")
-
+// CloseDB closes the connection pool, waiting for queries in progress to finish.
+func CloseDB() {
+	if DB == nil {
+		return
+	}
+	if err := DB.Close(); err != nil {
+		slog.Error("failed to close database", "error", err)
+		return
+	}
+	slog.Info("database connection closed")
 }
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect hides the SQL differences between the supported databases.
+// Repositories write queries with ? placeholders and call Rebind before
+// running them.
+type Dialect interface {
+	Name() string
+	// Rebind rewrites ? placeholders into the driver's bind syntax.
+	Rebind(query string) string
+	// SupportsReturning reports whether INSERT/UPDATE ... RETURNING works.
+	SupportsReturning() bool
+	// ILike returns a case-insensitive LIKE condition on column whose
+	// pattern escapes wildcards with a backslash.
+	ILike(column string) string
+	// IsUniqueViolation reports whether err is a unique constraint failure.
+	IsUniqueViolation(err error) bool
+}
+
+// DialectFor returns the Dialect for a DB_DRIVER value, or nil if unsupported.
+func DialectFor(driver string) Dialect {
+	switch driver {
+	case POSTGRES:
+		return postgresDialect{}
+	case MYSQL:
+		return mysqlDialect{}
+	case SQLITE:
+		return sqliteDialect{}
+	default:
+		return nil
+	}
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return POSTGRES }
+
+func (postgresDialect) Rebind(query string) string {
+	var b strings.Builder
+	n := 0
+	inQuote := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+		case r == '?' && !inQuote:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (postgresDialect) SupportsReturning() bool { return true }
+
+func (postgresDialect) ILike(column string) string { return column + " ILIKE ?" }
+
+func (postgresDialect) IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return MYSQL }
+
+func (mysqlDialect) Rebind(query string) string { return query }
+
+func (mysqlDialect) SupportsReturning() bool { return false }
+
+// utf8mb4 default collations are already case-insensitive
+func (mysqlDialect) ILike(column string) string { return column + " LIKE ?" }
+
+func (mysqlDialect) IsUniqueViolation(err error) bool {
+	var myErr *mysql.MySQLError
+	return errors.As(err, &myErr) && myErr.Number == 1062
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return SQLITE }
+
+func (sqliteDialect) Rebind(query string) string { return query }
+
+// RETURNING needs SQLite 3.35+, which the bundled driver ships
+func (sqliteDialect) SupportsReturning() bool { return true }
+
+// LIKE is case-insensitive for ASCII but has no default escape character
+func (sqliteDialect) ILike(column string) string { return column + ` LIKE ? ESCAPE '\'` }
+
+func (sqliteDialect) IsUniqueViolation(err error) bool {
+	var liteErr sqlite3.Error
+	return errors.As(err, &liteErr) && liteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
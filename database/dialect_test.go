@@ -0,0 +1,94 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestPostgresRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"WHERE id = ?", "WHERE id = $1"},
+		{"VALUES (?, ?, ?)", "VALUES ($1, $2, $3)"},
+		{"WHERE a = ? AND b = ? LIMIT ?", "WHERE a = $1 AND b = $2 LIMIT $3"},
+		// placeholders inside string literals are left alone
+		{"WHERE title = '?' AND id = ?", "WHERE title = '?' AND id = $1"},
+		{`WHERE title LIKE ? ESCAPE '\'`, `WHERE title LIKE $1 ESCAPE '\'`},
+		// a doubled quote is an escaped quote, not the end of the literal
+		{"WHERE title = 'it''s ?' AND id = ?", "WHERE title = 'it''s ?' AND id = $1"},
+	}
+
+	for _, tt := range tests {
+		if got := (postgresDialect{}).Rebind(tt.query); got != tt.want {
+			t.Errorf("Rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRebindKeepsPlaceholders(t *testing.T) {
+	query := "WHERE a = ? AND b = '?'"
+	for _, d := range []Dialect{mysqlDialect{}, sqliteDialect{}} {
+		if got := d.Rebind(query); got != query {
+			t.Errorf("%s: Rebind(%q) = %q, want it unchanged", d.Name(), query, got)
+		}
+	}
+}
+
+func TestILike(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{postgresDialect{}, "title ILIKE ?"},
+		{mysqlDialect{}, "title LIKE ?"},
+		{sqliteDialect{}, `title LIKE ? ESCAPE '\'`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.ILike("title"); got != tt.want {
+			t.Errorf("%s: ILike = %q, want %q", tt.dialect.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	notDB := errors.New("connection refused")
+
+	tests := []struct {
+		dialect Dialect
+		unique  error
+		other   error
+	}{
+		{postgresDialect{}, &pq.Error{Code: "23505"}, &pq.Error{Code: "23503"}},
+		{mysqlDialect{}, &mysql.MySQLError{Number: 1062}, &mysql.MySQLError{Number: 1452}},
+		{
+			sqliteDialect{},
+			sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique},
+			sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey},
+		},
+	}
+
+	for _, tt := range tests {
+		name := tt.dialect.Name()
+		if !tt.dialect.IsUniqueViolation(tt.unique) {
+			t.Errorf("%s: unique violation not detected", name)
+		}
+		if !tt.dialect.IsUniqueViolation(fmt.Errorf("insert user: %w", tt.unique)) {
+			t.Errorf("%s: wrapped unique violation not detected", name)
+		}
+		if tt.dialect.IsUniqueViolation(tt.other) {
+			t.Errorf("%s: other constraint error reported as a unique violation", name)
+		}
+		if tt.dialect.IsUniqueViolation(notDB) || tt.dialect.IsUniqueViolation(nil) {
+			t.Errorf("%s: non-database error reported as a unique violation", name)
+		}
+	}
+}
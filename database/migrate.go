@@ -0,0 +1,130 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"example/go-web-gin/config"
+	"example/go-web-gin/migrations"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrations are embedded per driver and applied with golang-migrate, so the
+// schema_migrations table is the same one the migrate CLI uses. While it
+// runs, golang-migrate holds a database lock (pg_advisory_lock on Postgres,
+// GET_LOCK on MySQL): replicas booting together wait for the first one and
+// then find nothing left to apply.
+
+// NewMigrator returns a migrator over the embedded migrations for driver.
+// Closing it also closes db.
+func NewMigrator(db *sql.DB, driver string) (*migrate.Migrate, error) {
+	src, err := iofs.New(migrations.FS, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	var instance migratedb.Driver
+	switch driver {
+	case POSTGRES:
+		instance, err = postgres.WithInstance(db, &postgres.Config{})
+	case MYSQL:
+		instance, err = mysql.WithInstance(db, &mysql.Config{})
+	case SQLITE:
+		instance, err = sqlite3.WithInstance(db, &sqlite3.Config{})
+	default:
+		err = fmt.Errorf("unsupported DB driver: %q", driver)
+	}
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, driver, instance)
+	if err != nil {
+		return nil, err
+	}
+	m.Log = migrateLogger{}
+	return m, nil
+}
+
+// AutoMigrate applies pending migrations at boot on a dedicated connection
+// pool, which is closed afterwards.
+func AutoMigrate(cfg config.DBConfig) error {
+	db, err := Open(cfg)
+	if err != nil {
+		return err
+	}
+
+	m, err := NewMigrator(db, cfg.Driver)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	defer m.Close()
+
+	return up(m)
+}
+
+// migrateSQLite applies the SQLite migrations on the app's own pool. The
+// migrator is not closed, as that would close db.
+func migrateSQLite(db *sql.DB) error {
+	m, err := NewMigrator(db, SQLITE)
+	if err != nil {
+		return err
+	}
+	return up(m)
+}
+
+func up(m *migrate.Migrate) error {
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	slog.Info("database schema migrated", "version", version, "dirty", dirty)
+	return nil
+}
+
+// LatestMigration returns the highest migration version embedded for driver.
+func LatestMigration(driver string) (uint, error) {
+	src, err := iofs.New(migrations.FS, driver)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	for err == nil {
+		var next uint
+		next, err = src.Next(version)
+		if err == nil {
+			version = next
+		}
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+	return version, nil
+}
+
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	slog.Info("migrate: " + strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"example/go-web-gin/config"
+	"testing"
+)
+
+func TestMigrationsBackfillUpdatedAt(t *testing.T) {
+	db, err := Open(config.DBConfig{Driver: SQLITE, Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// the migrator is not closed, as that would close db
+	m, err := NewMigrator(db, SQLITE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Migrate(5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO albums (title, artist, price, created_at) VALUES ('Jeru', 'Gerry Mulligan', 17.99, '2020-01-02 03:04:05')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := up(m); err != nil {
+		t.Fatal(err)
+	}
+
+	var updatedAt string
+	if err := db.QueryRow(`SELECT updated_at FROM albums`).Scan(&updatedAt); err != nil {
+		t.Fatal(err)
+	}
+	if updatedAt != "2020-01-02T03:04:05Z" {
+		t.Errorf("updated_at of an existing album is %s, want its created_at", updatedAt)
+	}
+
+	// every migration can be rolled back and applied again
+	if err := m.Down(); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if err := up(m); err != nil {
+		t.Fatalf("up again: %v", err)
+	}
+}
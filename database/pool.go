@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"example/go-web-gin/config"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// maxConnectBackoff caps the wait between boot-time connection attempts.
+const maxConnectBackoff = 10 * time.Second
+
+// configurePool applies the pool limits from cfg.
+func configurePool(db *sql.DB, cfg config.DBConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+// PingWithRetry pings db until it answers, waiting backoff before the first
+// retry and doubling the wait after each failure. It gives up after retries
+// retries or when ctx is cancelled.
+func PingWithRetry(ctx context.Context, db *sql.DB, retries int, backoff time.Duration) error {
+	for attempt := 0; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= retries {
+			return fmt.Errorf("database not reachable after %d attempts: %w", attempt+1, err)
+		}
+
+		slog.WarnContext(ctx, "database not ready, retrying",
+			"attempt", attempt+1,
+			"max_attempts", retries+1,
+			"retry_in", backoff.String(),
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
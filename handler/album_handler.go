@@ -1,8 +1,9 @@
 package handler
 
 import (
-	"database/sql"
+	"encoding/json"
 	"net/http"
+	"time"
 
 	"example/go-web-gin/model"
 	"example/go-web-gin/service"
@@ -20,25 +21,76 @@ func NewAlbumHandler(service *service.AlbumService) *AlbumHandler {
 
 // GetAllAlbums godoc
 // @Summary Get all albums
-// @Description Retrieves a list of all albums
+// @Description Retrieves a page of albums, optionally filtered and sorted. Pass next_cursor from the previous page as cursor, with the same sort and order, to fetch the next one.
 // @Tags albums
 // @Accept json
 // @Produce json
-// @Success 200 {array} model.Album
-// @Failure 500 {object} map[string]string
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param artist query string false "Case-insensitive substring match on artist"
+// @Param title query string false "Case-insensitive substring match on title"
+// @Param min_price query number false "Minimum price (inclusive)"
+// @Param max_price query number false "Maximum price (inclusive)"
+// @Param updated_since query string false "Only albums changed at or after this RFC 3339 time, for incremental sync" format(date-time)
+// @Param sort query string false "Sort field" Enums(id, title, artist, price)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param If-None-Match header string false "ETag of a cached copy of this page"
+// @Success 200 {object} model.AlbumPage
+// @Header 200 {string} ETag "Hash of the page"
+// @Success 304 "Not Modified: the cached copy is current"
+// @Failure 400 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
 // @Router /albums [get]
 func (h *AlbumHandler) GetAllAlbums(c *gin.Context) {
-	albums, err := h.service.GetAllAlbums()
+	var query model.AlbumQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	page, err := h.service.GetAllAlbums(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	body, err := json.Marshal(page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	// a page has no single modification time: deletes change it too
+	sendConditional(c, contentETag(body), time.Time{}, body)
+}
+
+// SearchAlbums godoc
+// @Summary Search albums
+// @Description Full-text search over album title and artist, ranked by relevance with highlighted matches. Falls back to fuzzy trigram matching when there are no exact word matches.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param q query string true "Search terms"
+// @Param limit query int false "Maximum results (1-50, default 20)"
+// @Success 200 {object} model.AlbumSearchResults
+// @Failure 400 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
+// @Router /albums/search [get]
+func (h *AlbumHandler) SearchAlbums(c *gin.Context) {
+	var query model.AlbumSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(bindError(err))
 		return
 	}
 
-	if albums == nil {
-		albums = []model.Album{}
+	results, err := h.service.SearchAlbums(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	c.JSON(http.StatusOK, albums)
+	c.JSON(http.StatusOK, model.AlbumSearchResults{Data: results})
 }
 
 // GetAlbumByID godoc
@@ -48,22 +100,29 @@ func (h *AlbumHandler) GetAllAlbums(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Album ID"
+// @Param If-None-Match header string false "ETag of a cached copy of the album"
+// @Param If-Modified-Since header string false "Last-Modified of a cached copy of the album"
 // @Success 200 {object} model.Album
-// @Failure 404 {object} map[string]string
+// @Header 200 {string} ETag "Album version, to send as If-Match when changing it"
+// @Header 200 {string} Last-Modified "When the album was last changed"
+// @Success 304 "Not Modified: the cached copy is current"
+// @Failure 404 {object} model.Problem
 // @Router /albums/{id} [get]
 func (h *AlbumHandler) GetAlbumByID(c *gin.Context) {
 	id := c.Param("id")
 
-	album, err := h.service.GetAlbumByID(id)
+	album, err := h.service.GetAlbumByID(c.Request.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "album not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, album)
+
+	body, err := json.Marshal(album)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	sendConditional(c, albumETag(album.Version), album.UpdatedAt, body)
 }
 
 // PostAlbum godoc
@@ -72,116 +131,168 @@ func (h *AlbumHandler) GetAlbumByID(c *gin.Context) {
 // @Tags albums
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param album body model.Album true "Album data"
 // @Success 201 {object} model.Album
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 201 {string} ETag "Album version, to send as If-Match when changing it"
+// @Failure 400 {object} model.Problem
+// @Failure 401 {object} model.Problem
+// @Failure 403 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
 // @Router /albums [post]
 func (h *AlbumHandler) PostAlbum(c *gin.Context) {
 	var newAlbum model.Album
 	if err := c.ShouldBindJSON(&newAlbum); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(bindError(err))
 		return
 	}
 
-	album, err := h.service.CreateAlbum(newAlbum)
+	album, err := h.service.CreateAlbum(c.Request.Context(), newAlbum)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
+	c.Header("ETag", albumETag(album.Version))
 	c.JSON(http.StatusCreated, album)
 }
 
 // UpdateAlbum godoc
 // @Summary Update an album
-// @Description Updates an existing album by ID
+// @Description Updates an existing album by ID. If-Match must carry the ETag the album was
+// @Description last read with (or *); the update is refused with 412 if the album has changed since.
 // @Tags albums
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Album ID"
+// @Param If-Match header string true "ETag of the album being replaced, or *"
 // @Param album body model.Album true "Album data"
 // @Success 200 {object} model.Album
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 200 {string} ETag "New album version"
+// @Failure 400 {object} model.Problem
+// @Failure 401 {object} model.Problem
+// @Failure 403 {object} model.Problem
+// @Failure 404 {object} model.Problem
+// @Failure 412 {object} model.Problem
+// @Failure 428 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
 // @Router /albums/{id} [put]
 func (h *AlbumHandler) UpdateAlbum(c *gin.Context) {
 	id := c.Param("id")
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	var updatedAlbum model.Album
 	if err := c.ShouldBindJSON(&updatedAlbum); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(bindError(err))
 		return
 	}
 
-	album, err := h.service.UpdateAlbum(id, updatedAlbum)
+	album, err := h.service.UpdateAlbum(c.Request.Context(), id, updatedAlbum, version)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "album not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
+	c.Header("ETag", albumETag(album.Version))
 	c.JSON(http.StatusOK, album)
 }
 
 // DeleteAlbum godoc
 // @Summary Delete an album
-// @Description Deletes an album by ID
+// @Description Deletes an album by ID. Deleting is idempotent: once an album is gone,
+// @Description repeating the request leaves it gone but answers 404 instead of 204.
+// @Description If-Match must carry the album's current ETag (or *), as for PUT.
 // @Tags albums
-// @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Album ID"
-// @Success 200 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Param If-Match header string true "ETag of the album being deleted, or *"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.Problem
+// @Failure 401 {object} model.Problem
+// @Failure 403 {object} model.Problem
+// @Failure 404 {object} model.Problem
+// @Failure 412 {object} model.Problem
+// @Failure 428 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
 // @Router /albums/{id} [delete]
 func (h *AlbumHandler) DeleteAlbum(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.service.DeleteAlbum(id)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.service.DeleteAlbum(c.Request.Context(), id, version)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "album not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "album deleted successfully"})
+	c.Status(http.StatusNoContent)
 }
 
 // PatchAlbum godoc
 // @Summary Partially update an album
-// @Description Partially updates an existing album by ID
+// @Description Applies a JSON Merge Patch (RFC 7386) or a JSON Patch (RFC 6902) to an album, chosen by
+// @Description Content-Type; a plain JSON body is read as a merge patch. The patched album must pass the
+// @Description same validation as PUT, and only the fields it changes are written. If-Match must carry
+// @Description the album's current ETag (or *), as for PUT.
 // @Tags albums
-// @Accept json
+// @Accept json,application/merge-patch+json,application/json-patch+json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Album ID"
-// @Param album body model.Album true "Partial album data"
+// @Param If-Match header string true "ETag of the album being patched, or *"
+// @Param album body model.AlbumPatch true "Merge patch, or an array of JSON Patch operations"
 // @Success 200 {object} model.Album
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 200 {string} ETag "New album version"
+// @Header 200 {string} Accept-Patch "Supported patch formats"
+// @Failure 400 {object} model.Problem
+// @Failure 401 {object} model.Problem
+// @Failure 403 {object} model.Problem
+// @Failure 404 {object} model.Problem
+// @Failure 409 {object} model.Problem "A JSON Patch test operation failed"
+// @Failure 412 {object} model.Problem
+// @Failure 415 {object} model.Problem
+// @Failure 428 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
 // @Router /albums/{id} [patch]
 func (h *AlbumHandler) PatchAlbum(c *gin.Context) {
 	id := c.Param("id")
+	c.Header("Accept-Patch", acceptPatch)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	var patchAlbum model.Album
-	if err := c.ShouldBindJSON(&patchAlbum); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	body, err := c.GetRawData()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	apply, err := albumPatcher(c.ContentType(), body)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	album, err := h.service.PatchAlbum(id, patchAlbum)
+	album, err := h.service.PatchAlbum(c.Request.Context(), id, version, apply)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "album not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
+	c.Header("ETag", albumETag(album.Version))
 	c.JSON(http.StatusOK, album)
 }
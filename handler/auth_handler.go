@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"example/go-web-gin/model"
 	"example/go-web-gin/service"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -15,22 +18,143 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 	return &AuthHandler{authService: authService}
 }
 
+// Register godoc
+// @Summary Register a new user
+// @Description Creates a user account that can log in with the given email and password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param user body model.RegisterRequest true "Account data"
+// @Success 201 {object} model.User
+// @Failure 400 {object} model.Problem
+// @Failure 409 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
+// @Router /auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req model.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	user, err := h.authService.Register(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Exchanges email and password for a JWT access token and a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body model.LoginRequest true "Credentials"
+// @Success 200 {object} model.TokenPair
+// @Failure 400 {object} model.Problem
+// @Failure 401 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
+// @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
-	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	tokens, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchanges a refresh token for a new access token and refresh token. Each refresh token can be used once; reusing one revokes every token issued from the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refresh body model.RefreshRequest true "Refresh token"
+// @Success 200 {object} model.TokenPair
+// @Failure 400 {object} model.Problem
+// @Failure 401 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(bindError(err))
 		return
 	}
 
-	token, err := h.authService.Login(req.Email, req.Password)
+	tokens, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revokes the access token used for this request and, when given, the refresh token from the same login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param logout body model.LogoutRequest false "Refresh token to revoke"
+// @Success 204
+// @Failure 400 {object} model.Problem
+// @Failure 401 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req model.LogoutRequest
+	// the body is optional; an empty one decodes to io.EOF. ContentLength
+	// cannot tell, as it is -1 for chunked bodies.
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(bindError(err))
+		return
+	}
+
+	claims := c.MustGet("claims").(*service.Claims)
+	if err := h.authService.Logout(c.Request.Context(), claims, req.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeUserTokens godoc
+// @Summary Revoke all tokens for a user
+// @Description Invalidates every access and refresh token issued to the user so far
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 401 {object} model.Problem
+// @Failure 403 {object} model.Problem
+// @Failure 500 {object} model.Problem
+// @Failure 504 {object} model.Problem
+// @Router /admin/users/{id}/revoke-tokens [post]
+func (h *AuthHandler) RevokeUserTokens(c *gin.Context) {
+	if err := h.authService.RevokeAllForUser(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.Status(http.StatusNoContent)
 }
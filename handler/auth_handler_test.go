@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"example/go-web-gin/cache"
+	"example/go-web-gin/middleware"
+	"example/go-web-gin/model"
+	"example/go-web-gin/service"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeUsers is an in-memory repositories.UserRepository.
+type fakeUsers struct {
+	mu    sync.Mutex
+	users []model.User
+}
+
+func (f *fakeUsers) Create(ctx context.Context, user model.User) (model.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user.ID = strconv.Itoa(len(f.users) + 1)
+	f.users = append(f.users, user)
+	return user, nil
+}
+
+func (f *fakeUsers) FindByEmail(ctx context.Context, email string) (model.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return model.User{}, sql.ErrNoRows
+}
+
+func (f *fakeUsers) FindByID(ctx context.Context, id string) (model.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return model.User{}, sql.ErrNoRows
+}
+
+func newAuthRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ttls := service.TokenTTLs{Access: time.Hour, Refresh: time.Hour}
+	authService := service.NewAuthService([]byte("test-secret-test-secret-test-secret"), ttls, &fakeUsers{}, cache.NewMemory(0), logger)
+	h := NewAuthHandler(authService)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.POST("/auth/register", h.Register)
+	r.POST("/auth/login", h.Login)
+	r.POST("/auth/refresh", h.Refresh)
+	r.POST("/auth/logout", middleware.JWTAuth(authService), h.Logout)
+	return r
+}
+
+func doJSON(r http.Handler, path, token string, body any) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func login(t *testing.T, r http.Handler) model.TokenPair {
+	t.Helper()
+	creds := map[string]string{"email": "jane@example.com", "password": "password123"}
+	if w := doJSON(r, "/auth/register", "", creds); w.Code != http.StatusCreated {
+		t.Fatalf("register: status %d: %s", w.Code, w.Body)
+	}
+	w := doJSON(r, "/auth/login", "", creds)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status %d: %s", w.Code, w.Body)
+	}
+	var tokens model.TokenPair
+	if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+func TestLogoutRevokesRefreshToken(t *testing.T) {
+	r := newAuthRouter(t)
+	tokens := login(t, r)
+
+	w := doJSON(r, "/auth/logout", tokens.AccessToken, model.LogoutRequest{RefreshToken: tokens.RefreshToken})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("logout: status %d: %s", w.Code, w.Body)
+	}
+
+	w = doJSON(r, "/auth/refresh", "", model.RefreshRequest{RefreshToken: tokens.RefreshToken})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after logout: status %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+}
+
+func TestLogoutRejectsMalformedBody(t *testing.T) {
+	r := newAuthRouter(t)
+	tokens := login(t, r)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", bytes.NewBufferString("{oops"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
+
+func TestLogoutRevokesRefreshTokenFromChunkedBody(t *testing.T) {
+	r := newAuthRouter(t)
+	tokens := login(t, r)
+
+	data, _ := json.Marshal(model.LogoutRequest{RefreshToken: tokens.RefreshToken})
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	// a chunked body has no length up front
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("logout: status %d: %s", w.Code, w.Body)
+	}
+
+	w = doJSON(r, "/auth/refresh", "", model.RefreshRequest{RefreshToken: tokens.RefreshToken})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after chunked logout: status %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+}
+
+func TestLogoutWithoutBody(t *testing.T) {
+	r := newAuthRouter(t)
+	tokens := login(t, r)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("logout without body: status %d: %s", w.Code, w.Body)
+	}
+
+	// the refresh token was not named, so it stays usable
+	w = doJSON(r, "/auth/refresh", "", model.RefreshRequest{RefreshToken: tokens.RefreshToken})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh after logout without body: status %d: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"example/go-web-gin/apperr"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// albumETag is the entity tag for an album at version. It changes on every
+// write, so it is a strong validator.
+func albumETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion reads the album version a write is conditional on from the
+// If-Match header. "*" matches any version and yields 0. The header is
+// required so clients cannot overwrite changes they have not seen.
+func ifMatchVersion(c *gin.Context) (int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, apperr.PreconditionRequired("If-Match header with the album's ETag is required")
+	}
+	if header == "*" {
+		return 0, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, apperr.Validation("If-Match must hold a single ETag",
+			apperr.FieldError{Field: "If-Match", Message: "must be one ETag or *"},
+		)
+	}
+
+	// If-Match uses strong comparison, so a weak or foreign tag never matches
+	mismatch := apperr.PreconditionFailed("If-Match does not match the album's current ETag")
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, mismatch
+	}
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, mismatch
+	}
+	return version, nil
+}
+
+// contentETag is an entity tag for a response without a version of its own,
+// derived from its encoded body.
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// sendConditional answers a GET with the JSON body and its validators, or
+// with 304 Not Modified if the client's copy is still current. lastModified
+// may be zero when the response has no meaningful modification time.
+func sendConditional(c *gin.Context, etag string, lastModified time.Time, body []byte) {
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// notModified evaluates If-None-Match and, only when that is absent,
+// If-Modified-Since, as RFC 9110 section 13.2.2 orders them.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etagListMatches(header, etag)
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP dates only have second precision
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagListMatches reports whether an If-None-Match list holds etag. The
+// comparison is weak, so W/ prefixes are ignored.
+func etagListMatches(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"example/go-web-gin/model"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+type HealthHandler struct {
+	db *sql.DB
+	// redis is nil when the app runs without Redis
+	redis   *redis.Client
+	timeout time.Duration
+}
+
+func NewHealthHandler(db *sql.DB, redis *redis.Client, timeout time.Duration) *HealthHandler {
+	return &HealthHandler{db: db, redis: redis, timeout: timeout}
+}
+
+// Liveness reports that the process is up and serving requests.
+// It checks no dependencies so a database outage does not restart the pod.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, model.HealthStatus{Status: "ok"})
+}
+
+// Readiness pings every dependency and returns 503 if a required one is down.
+// The database entry also carries connection pool stats.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	checks := map[string]func(ctx context.Context) error{
+		"database": h.db.PingContext,
+	}
+	if h.redis != nil {
+		checks["redis"] = func(ctx context.Context) error {
+			return h.redis.Ping(ctx).Err()
+		}
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = model.HealthStatus{Status: "ok", Dependencies: map[string]model.DependencyStatus{}}
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dep := h.run(c.Request.Context(), name, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Dependencies[name] = dep
+			if dep.Required && dep.Status != "up" {
+				result.Status = "unavailable"
+			}
+		}()
+	}
+	wg.Wait()
+
+	db := result.Dependencies["database"]
+	db.Pool = poolStats(h.db.Stats())
+	result.Dependencies["database"] = db
+
+	status := http.StatusOK
+	if result.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, result)
+}
+
+// run checks one dependency. The probe is unauthenticated, so the error it
+// reports is a fixed string; the driver's message, which may name internal
+// hosts, only goes to the log.
+func (h *HealthHandler) run(parent context.Context, name string, check func(ctx context.Context) error) model.DependencyStatus {
+	ctx, cancel := context.WithTimeout(parent, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	dep := model.DependencyStatus{
+		Status:    "up",
+		Required:  true,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		slog.WarnContext(parent, "readiness check failed", "dependency", name, "error", err)
+		dep.Status = "down"
+		dep.Error = "ping failed"
+		if errors.Is(err, context.DeadlineExceeded) {
+			dep.Error = "timeout"
+		}
+	}
+	return dep
+}
+
+func poolStats(s sql.DBStats) *model.PoolStats {
+	return &model.PoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationMs:     float64(s.WaitDuration.Microseconds()) / 1000,
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"example/go-web-gin/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestReadinessHidesDependencyErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// nothing listens on port 1
+	const redisAddr = "127.0.0.1:1"
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr, MaxRetries: -1})
+	defer rdb.Close()
+
+	r := gin.New()
+	r.GET("/readyz", NewHealthHandler(db, rdb, time.Second).Readiness)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want %d: %s", w.Code, http.StatusServiceUnavailable, w.Body)
+	}
+	if strings.Contains(w.Body.String(), redisAddr) {
+		t.Errorf("body leaks the Redis address: %s", w.Body)
+	}
+	var status model.HealthStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if got := status.Dependencies["redis"]; got.Status != "down" || got.Error != "ping failed" {
+		t.Errorf("redis = %+v, want down with a fixed error", got)
+	}
+	if got := status.Dependencies["database"]; got.Status != "up" || got.Error != "" {
+		t.Errorf("database = %+v, want up", got)
+	}
+}
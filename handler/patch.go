@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"example/go-web-gin/apperr"
+	"example/go-web-gin/model"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// acceptPatch lists the patch formats for the Accept-Patch header (RFC 5789).
+const acceptPatch = mergePatchType + ", " + jsonPatchType
+
+// albumPatcher returns a function that applies a PATCH body in the given
+// format to an album and validates the result. A plain JSON body is read as
+// a merge patch, which is how partial bodies were always meant.
+func albumPatcher(contentType string, body []byte) (func(model.Album) (model.Album, error), error) {
+	var apply func(doc []byte) ([]byte, error)
+	switch contentType {
+	case mergePatchType, binding.MIMEJSON:
+		apply = func(doc []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(doc, body)
+		}
+	case jsonPatchType:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, &apperr.Error{Kind: apperr.KindValidation, Detail: "request body is not a valid JSON Patch", Err: err}
+		}
+		apply = patch.Apply
+	default:
+		return nil, apperr.UnsupportedMediaType("PATCH accepts " + acceptPatch)
+	}
+
+	return func(album model.Album) (model.Album, error) {
+		doc, err := json.Marshal(album)
+		if err != nil {
+			return model.Album{}, err
+		}
+		patched, err := apply(doc)
+		if err != nil {
+			return model.Album{}, patchError(err)
+		}
+
+		var result model.Album
+		dec := json.NewDecoder(bytes.NewReader(patched))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&result); err != nil {
+			return model.Album{}, bindError(err)
+		}
+		if err := binding.Validator.ValidateStruct(&result); err != nil {
+			return model.Album{}, bindError(err)
+		}
+		return result, nil
+	}, nil
+}
+
+// patchError explains why a patch could not be applied.
+func patchError(err error) error {
+	switch {
+	case errors.Is(err, jsonpatch.ErrBadJSONPatch):
+		return &apperr.Error{Kind: apperr.KindValidation, Detail: "request body is not valid JSON", Err: err}
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		// the album is not in the state the patch expects (RFC 5789 section 2.2)
+		return &apperr.Error{Kind: apperr.KindConflict, Detail: "a JSON Patch test operation failed", Err: err}
+	default:
+		return &apperr.Error{Kind: apperr.KindValidation, Detail: "patch cannot be applied to the album", Err: err}
+	}
+}
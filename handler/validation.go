@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"example/go-web-gin/apperr"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// report fields by their JSON or query name rather than the Go field name
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+const unknownFieldPrefix = "json: unknown field "
+
+// bindError turns a failed ShouldBindJSON or ShouldBindQuery into a
+// validation error listing each invalid field.
+func bindError(err error) error {
+	var (
+		invalid   validator.ValidationErrors
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+		timeErr   *time.ParseError
+	)
+	switch {
+	case errors.As(err, &invalid):
+		fields := make([]apperr.FieldError, 0, len(invalid))
+		for _, fe := range invalid {
+			fields = append(fields, apperr.FieldError{Field: fe.Field(), Message: fieldMessage(fe)})
+		}
+		return &apperr.Error{Kind: apperr.KindValidation, Detail: "request has invalid fields", Fields: fields, Err: err}
+
+	case errors.As(err, &typeErr):
+		return &apperr.Error{
+			Kind:   apperr.KindValidation,
+			Detail: "request has invalid fields",
+			Fields: []apperr.FieldError{{Field: typeErr.Field, Message: "must be " + jsonTypeName(typeErr.Type.Kind())}},
+			Err:    err,
+		}
+
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		// encoding/json has no error type for fields rejected by DisallowUnknownFields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldPrefix), `"`)
+		return &apperr.Error{
+			Kind:   apperr.KindValidation,
+			Detail: "request has invalid fields",
+			Fields: []apperr.FieldError{{Field: field, Message: "is not a known field"}},
+			Err:    err,
+		}
+
+	case errors.As(err, &timeErr):
+		// the binding error does not say which field held the time
+		return &apperr.Error{Kind: apperr.KindValidation, Detail: "times must be RFC 3339, e.g. 2024-05-01T12:00:00Z", Err: err}
+
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return &apperr.Error{Kind: apperr.KindValidation, Detail: "request body is not valid JSON", Err: err}
+
+	default:
+		return &apperr.Error{Kind: apperr.KindValidation, Detail: "request could not be parsed", Err: err}
+	}
+}
+
+func jsonTypeName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int64, reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), unit)
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	default:
+		return "is invalid"
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "albumapi"
+
+var (
+	// HTTPRequests counts finished requests by route template, not raw path,
+	// so album IDs do not create a series each.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// AlbumCacheRequests counts single-album cache lookups by result ("hit" or "miss").
+	AlbumCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "album_cache_requests_total",
+		Help:      "Album cache lookups by result.",
+	}, []string{"result"})
+
+	// LoginAttempts counts logins by result: "success", "failure" for bad
+	// credentials, or "error" when the check itself failed.
+	LoginAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_attempts_total",
+		Help:      "Login attempts by result.",
+	}, []string{"result"})
+)
+
+// RegisterDB exports the connection pool stats of db.
+func RegisterDB(db *sql.DB, driver string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, driver))
+}
+
+// Handler serves the registered metrics in the Prometheus text format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
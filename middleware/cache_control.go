@@ -0,0 +1,15 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// CacheControl sets the Cache-Control header for routes keyed by
+// "METHOD /full/path". ErrorHandler replaces it on error responses so that
+// problems are never cached.
+func CacheControl(perRoute map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if value, ok := perRoute[c.Request.Method+" "+c.FullPath()]; ok {
+			c.Header("Cache-Control", value)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"example/go-web-gin/apperr"
+	"example/go-web-gin/model"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+var kindStatus = map[apperr.Kind]int{
+	apperr.KindNotFound:             http.StatusNotFound,
+	apperr.KindValidation:           http.StatusBadRequest,
+	apperr.KindConflict:             http.StatusConflict,
+	apperr.KindUnauthorized:         http.StatusUnauthorized,
+	apperr.KindForbidden:            http.StatusForbidden,
+	apperr.KindUnavailable:          http.StatusServiceUnavailable,
+	apperr.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperr.KindPreconditionRequired: http.StatusPreconditionRequired,
+	apperr.KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+}
+
+// ErrorHandler renders the last error a handler recorded with c.Error as an
+// RFC 7807 problem. An *apperr.Error maps to its kind's status and detail;
+// anything else is logged and answered with a generic 500 (or 504 if the
+// request ran out of time) so internal error text never reaches the client.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		problem := problemFor(c, c.Errors.Last().Err)
+		c.Header("Content-Type", problemContentType)
+		c.Header("Cache-Control", "no-store")
+		c.JSON(problem.Status, problem)
+	}
+}
+
+func problemFor(c *gin.Context, err error) model.Problem {
+	ctx := c.Request.Context()
+	problem := model.Problem{
+		Type:      "about:blank",
+		Instance:  c.Request.URL.Path,
+		RequestID: c.GetString("request_id"),
+	}
+
+	if e, ok := apperr.As(err); ok && e.Kind != apperr.KindInternal {
+		problem.Status = kindStatus[e.Kind]
+		problem.Detail = e.Detail
+		problem.Errors = e.Fields
+		if e.Err != nil && e.Kind == apperr.KindUnavailable {
+			slog.WarnContext(ctx, "dependency unavailable", "error", e.Err)
+		}
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		slog.WarnContext(ctx, "request timed out", "error", err)
+		problem.Status = http.StatusGatewayTimeout
+		problem.Detail = "request timed out"
+	} else {
+		slog.ErrorContext(ctx, "request failed", "error", err)
+		problem.Status = http.StatusInternalServerError
+		problem.Detail = "an unexpected error occurred"
+	}
+
+	problem.Title = http.StatusText(problem.Status)
+	return problem
+}
+
+// abort records err for ErrorHandler and stops the handler chain.
+func abort(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
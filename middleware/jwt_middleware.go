@@ -1,9 +1,8 @@
 package middleware
 
 import (
+	"example/go-web-gin/apperr"
 	"example/go-web-gin/service"
-	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -13,24 +12,34 @@ func JWTAuth(authService *service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+			abort(c, apperr.Unauthorized("missing authorization header"))
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
-		fmt.Println(parts)
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header"})
+			abort(c, apperr.Unauthorized("invalid authorization header"))
 			return
 		}
 
-		userID, err := authService.ParseToken(parts[1])
+		claims, err := authService.ParseToken(parts[1])
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			abort(c, apperr.Unauthorized("invalid or expired token"))
 			return
 		}
 
-		c.Set("user_id", userID)
+		revoked, err := authService.IsRevoked(c.Request.Context(), claims)
+		if err != nil {
+			abort(c, apperr.Unavailable("unable to verify token", err))
+			return
+		}
+		if revoked {
+			abort(c, apperr.Unauthorized("token has been revoked"))
+			return
+		}
+
+		c.Set("user_id", claims.UserId)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
@@ -1,14 +1,20 @@
 package middleware
 
 import (
-	"log"
+	"example/go-web-gin/metrics"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RequestLogger logs method, path, status and latency for each request.
-func RequestLogger() gin.HandlerFunc {
+// RequestLogger logs one structured record per request and records it in
+// the HTTP metrics. Server errors log at error level and client errors at warn.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		// before request
@@ -20,6 +26,49 @@ func RequestLogger() gin.HandlerFunc {
 		// after request
 		latency := time.Since(start)
 		status := c.Writer.Status()
-		log.Printf("%s %s -> %d (%s)", method, path, status, latency)
+		route := c.FullPath()
+		if route == "" {
+			// unmatched paths share one series
+			route = "unmatched"
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(c.Request.Context(), level, "request",
+			slog.String("method", method),
+			slog.String("route", route),
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_id", c.GetString("user_id")),
+		)
+
+		labels := []string{method, route, strconv.Itoa(status)}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(latency.Seconds())
+	}
+}
+
+// Recovery turns a panic into a 500 and logs it with its stack trace.
+// It must run inside ErrorHandler, which renders the response.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				logger.ErrorContext(c.Request.Context(), "panic recovered",
+					"error", err,
+					"stack", string(debug.Stack()),
+				)
+				abort(c, fmt.Errorf("panic: %v", err))
+			}
+		}()
+		c.Next()
 	}
 }
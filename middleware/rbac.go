@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"example/go-web-gin/apperr"
+	"example/go-web-gin/model"
+	"example/go-web-gin/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows the request only if the token's role is one of roles.
+// It must run after JWTAuth.
+func RequireRole(roles ...model.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := claimsFrom(c)
+		if !ok {
+			abort(c, apperr.Unauthorized("authentication required"))
+			return
+		}
+
+		for _, role := range roles {
+			if claims.Role == role {
+				c.Next()
+				return
+			}
+		}
+		abort(c, apperr.Forbidden("insufficient role"))
+	}
+}
+
+// RequirePermission allows the request only if the token's role grants perm.
+// It must run after JWTAuth.
+func RequirePermission(perm model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := claimsFrom(c)
+		if !ok {
+			abort(c, apperr.Unauthorized("authentication required"))
+			return
+		}
+
+		if !claims.Role.Can(perm) {
+			abort(c, apperr.Forbidden("missing permission "+string(perm)))
+			return
+		}
+		c.Next()
+	}
+}
+
+func claimsFrom(c *gin.Context) (*service.Claims, bool) {
+	v, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	claims, ok := v.(*service.Claims)
+	return claims, ok
+}
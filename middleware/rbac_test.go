@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"example/go-web-gin/model"
+	"example/go-web-gin/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUsersManageIsAdminOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		role model.Role
+		want int
+	}{
+		{model.RoleAdmin, http.StatusNoContent},
+		{model.RoleEditor, http.StatusForbidden},
+		{model.RoleViewer, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		r := gin.New()
+		r.Use(ErrorHandler())
+		r.POST("/admin", func(c *gin.Context) {
+			c.Set("claims", &service.Claims{UserId: "1", Role: tt.role})
+		}, RequirePermission(model.PermUsersManage), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin", nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.role, w.Code, tt.want)
+		}
+	}
+}
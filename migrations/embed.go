@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migration files so the binary can apply
+// them without the migrate CLI. Each driver has its own directory.
+package migrations
+
+import "embed"
+
+//go:embed postgres/*.sql mysql/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,2 @@
+-- Drop search index
+DROP INDEX idx_albums_search ON albums;
@@ -0,0 +1,2 @@
+-- Full-text index over title and artist
+CREATE FULLTEXT INDEX idx_albums_search ON albums (title, artist);
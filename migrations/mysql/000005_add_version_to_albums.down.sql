@@ -0,0 +1,2 @@
+-- Drop version from albums
+ALTER TABLE albums DROP COLUMN version;
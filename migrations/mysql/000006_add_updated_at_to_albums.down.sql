@@ -0,0 +1,2 @@
+-- Drop updated_at from albums
+ALTER TABLE albums DROP COLUMN updated_at;
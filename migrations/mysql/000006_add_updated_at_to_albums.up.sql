@@ -0,0 +1,3 @@
+-- Add updated_at to albums; 000007 sets it for existing rows
+ALTER TABLE albums
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
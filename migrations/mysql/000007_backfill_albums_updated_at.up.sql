@@ -0,0 +1,4 @@
+-- Existing rows were last changed when created. This is a migration of its
+-- own because MySQL runs migrations one statement at a time. There is
+-- nothing to undo, so it has no down migration.
+UPDATE albums SET updated_at = created_at;
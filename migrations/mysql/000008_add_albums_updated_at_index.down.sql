@@ -0,0 +1,2 @@
+-- Drop updated_at index
+DROP INDEX idx_albums_updated_at ON albums;
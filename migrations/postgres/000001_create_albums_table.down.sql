@@ -0,0 +1,2 @@
+-- Drop albums table
+DROP TABLE IF EXISTS albums;
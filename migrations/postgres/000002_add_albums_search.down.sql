@@ -0,0 +1,5 @@
+-- Drop search indexes and column
+DROP INDEX IF EXISTS idx_albums_artist_trgm;
+DROP INDEX IF EXISTS idx_albums_title_trgm;
+DROP INDEX IF EXISTS idx_albums_search_vector;
+ALTER TABLE albums DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,14 @@
+-- Trigram matching for typo-tolerant search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Full-text document over title (weight A) and artist (weight B)
+ALTER TABLE albums
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(artist, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_albums_search_vector ON albums USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_albums_title_trgm ON albums USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_albums_artist_trgm ON albums USING GIN (artist gin_trgm_ops);
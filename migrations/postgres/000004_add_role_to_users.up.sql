@@ -0,0 +1,5 @@
+-- Add role to users; promote the first admin manually:
+-- UPDATE users SET role = 'admin' WHERE email = '...';
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'viewer'
+    CHECK (role IN ('admin', 'editor', 'viewer'));
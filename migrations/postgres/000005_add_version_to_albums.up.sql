@@ -0,0 +1,3 @@
+-- Add a version to albums, bumped on every write, for optimistic locking
+ALTER TABLE albums
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- Drop updated_at from albums
+ALTER TABLE albums DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,5 @@
+-- Existing rows were last changed when created. MySQL needs this backfill
+-- in a migration of its own, so it is split out here too to keep version
+-- numbers aligned with the MySQL migrations. There is nothing to undo, so
+-- it has no down migration.
+UPDATE albums SET updated_at = created_at;
@@ -0,0 +1,2 @@
+-- Drop updated_at index
+DROP INDEX IF EXISTS idx_albums_updated_at;
@@ -0,0 +1,8 @@
+-- Create albums table
+CREATE TABLE IF NOT EXISTS albums (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(50) NOT NULL,
+    artist VARCHAR(100) NOT NULL,
+    price NUMERIC(10,2) NOT NULL CHECK (price > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- Drop search indexes
+DROP INDEX IF EXISTS idx_albums_artist;
+DROP INDEX IF EXISTS idx_albums_title;
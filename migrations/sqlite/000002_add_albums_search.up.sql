@@ -0,0 +1,3 @@
+-- SQLite search uses substring matching; index the sortable text columns
+CREATE INDEX IF NOT EXISTS idx_albums_title ON albums (title);
+CREATE INDEX IF NOT EXISTS idx_albums_artist ON albums (artist);
@@ -0,0 +1,5 @@
+-- Add updated_at to albums; 000007 sets it for existing rows.
+-- SQLite cannot add a column defaulting to CURRENT_TIMESTAMP, so inserts
+-- set it explicitly.
+ALTER TABLE albums
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
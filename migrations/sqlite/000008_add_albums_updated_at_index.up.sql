@@ -0,0 +1,2 @@
+-- Index updated_at for the updated_since filter
+CREATE INDEX IF NOT EXISTS idx_albums_updated_at ON albums (updated_at);
@@ -1,9 +1,81 @@
 package model
 
+import "time"
+
 // album represents data about a record album.
 type Album struct {
 	ID     string  `json:"id" example:"1"`
 	Title  string  `json:"title" binding:"required,min=1,max=50" example:"My Album"`
 	Artist string  `json:"artist" binding:"required" example:"John Doe"`
 	Price  float64 `json:"price" binding:"required,gt=0" example:"19.99"`
+	// Version is bumped on every write and sent as the ETag header.
+	Version int64 `json:"-"`
+	// CreatedAt and UpdatedAt are set by the server: POST and PUT ignore
+	// them and PATCH may not change them. UpdatedAt changes on every write
+	// and is sent as the Last-Modified header.
+	CreatedAt time.Time `json:"created_at" readonly:"true" example:"2024-05-01T12:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" readonly:"true" example:"2024-05-02T08:30:00Z"`
+}
+
+// AlbumPatch holds the album fields a PATCH changes; nil fields are left as
+// stored. As a request body it is the shape of a JSON Merge Patch.
+type AlbumPatch struct {
+	Title  *string  `json:"title,omitempty" example:"My Album"`
+	Artist *string  `json:"artist,omitempty" example:"John Doe"`
+	Price  *float64 `json:"price,omitempty" example:"19.99"`
+}
+
+// Empty reports whether the patch changes nothing.
+func (p AlbumPatch) Empty() bool {
+	return p.Title == nil && p.Artist == nil && p.Price == nil
+}
+
+// AlbumQuery holds the filters, sort order and keyset cursor for listing albums.
+type AlbumQuery struct {
+	Limit    int      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor   string   `form:"cursor"`
+	Artist   string   `form:"artist"`
+	Title    string   `form:"title"`
+	MinPrice *float64 `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice *float64 `form:"max_price" binding:"omitempty,gte=0"`
+	// UpdatedSince keeps albums changed at or after this time, for clients
+	// syncing incrementally.
+	UpdatedSince *time.Time `form:"updated_since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort         string     `form:"sort" binding:"omitempty,oneof=id title artist price"`
+	Order        string     `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// AlbumPage is the response envelope for a page of albums.
+type AlbumPage struct {
+	Data       []Album `json:"data"`
+	NextCursor string  `json:"next_cursor,omitempty" example:"eyJzIjoicHJpY2UiLCJvIjoiQVNDIiwidiI6IjE5Ljk5IiwiaWQiOiI0MiJ9"`
+	Total      int     `json:"total" example:"100000"`
+}
+
+// AlbumSearchQuery holds the parameters for full-text album search.
+type AlbumSearchQuery struct {
+	Q     string `form:"q" binding:"required,min=1,max=100"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// AlbumSearchResult is an album matched by search, with its relevance.
+type AlbumSearchResult struct {
+	Album
+	Rank float64 `json:"rank" example:"0.6079"`
+	// Match is "fulltext" for full-text index hits, or "trigram" (PostgreSQL)
+	// or "substring" (other databases) for fallback hits.
+	Match     string          `json:"match" example:"fulltext"`
+	Highlight *AlbumHighlight `json:"highlight,omitempty"`
+}
+
+// AlbumHighlight holds title and artist, HTML-escaped, with matched terms
+// wrapped in <mark> tags.
+type AlbumHighlight struct {
+	Title  string `json:"title" example:"My <mark>Album</mark>"`
+	Artist string `json:"artist" example:"John Doe"`
+}
+
+// AlbumSearchResults is the response envelope for album search.
+type AlbumSearchResults struct {
+	Data []AlbumSearchResult `json:"data"`
 }
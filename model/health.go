@@ -0,0 +1,31 @@
+package model
+
+// DependencyStatus is the result of checking one backing service.
+type DependencyStatus struct {
+	Status    string  `json:"status" example:"up"`
+	Required  bool    `json:"required"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	// Error is "timeout" or "ping failed" when the dependency is down.
+	Error string `json:"error,omitempty" example:"ping failed"`
+	// Pool is only set for the database.
+	Pool *PoolStats `json:"pool,omitempty"`
+}
+
+// PoolStats is a snapshot of the database connection pool.
+type PoolStats struct {
+	MaxOpenConnections int     `json:"max_open_connections" example:"25"`
+	OpenConnections    int     `json:"open_connections" example:"3"`
+	InUse              int     `json:"in_use" example:"1"`
+	Idle               int     `json:"idle" example:"2"`
+	WaitCount          int64   `json:"wait_count"`
+	WaitDurationMs     float64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64   `json:"max_lifetime_closed"`
+}
+
+// HealthStatus is the body returned by the health endpoints.
+type HealthStatus struct {
+	Status       string                      `json:"status" example:"ok"`
+	Dependencies map[string]DependencyStatus `json:"dependencies,omitempty"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "example/go-web-gin/apperr"
+
+// Problem is an RFC 7807 problem details body, sent with the
+// application/problem+json content type for every error response.
+type Problem struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"album not found"`
+	Instance string `json:"instance,omitempty" example:"/api/v1/albums/42"`
+	// RequestID matches the X-Request-ID header, for finding the request in the logs.
+	RequestID string `json:"request_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	// Errors lists the invalid fields of a validation problem.
+	Errors []apperr.FieldError `json:"errors,omitempty"`
+}
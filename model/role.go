@@ -0,0 +1,37 @@
+package model
+
+// Role is the access level of a user, carried in the access token.
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+// Permission is a single action a role may be allowed to perform.
+type Permission string
+
+const (
+	PermAlbumsWrite  Permission = "albums:write"
+	PermAlbumsDelete Permission = "albums:delete"
+	PermUsersManage  Permission = "users:manage"
+)
+
+// rolePermissions maps each role to what it may do. Album reads are public
+// and need no permission.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin:  {PermAlbumsWrite, PermAlbumsDelete, PermUsersManage},
+	RoleEditor: {PermAlbumsWrite},
+	RoleViewer: {},
+}
+
+// Can reports whether the role grants the permission.
+func (r Role) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package model
+
+// User is a registered account that can log in.
+type User struct {
+	ID           string `json:"id" example:"1"`
+	Email        string `json:"email" example:"jane@example.com"`
+	Name         string `json:"name" example:"Jane Doe"`
+	Role         Role   `json:"role" example:"viewer"`
+	PasswordHash string `json:"-"`
+}
+
+// RegisterRequest is the payload for creating a new account.
+type RegisterRequest struct {
+	Email string `json:"email" binding:"required,email,max=255" example:"jane@example.com"`
+	// bcrypt only uses the first 72 bytes of a password
+	Password string `json:"password" binding:"required,min=8,max=72" example:"password123"`
+	Name     string `json:"name" binding:"max=100" example:"Jane Doe"`
+}
+
+// LoginRequest is the payload for exchanging credentials for a token.
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required" example:"jane@example.com"`
+	Password string `json:"password" binding:"required" example:"password123"`
+}
+
+// RefreshRequest is the payload for exchanging a refresh token for a new token pair.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest optionally carries the refresh token to revoke along with the access token.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenPair is returned on login and refresh.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	// ExpiresIn is the access token lifetime in seconds.
+	ExpiresIn int `json:"expires_in" example:"3600"`
+}
@@ -1,161 +1,434 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"example/go-web-gin/database"
 	"example/go-web-gin/model"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+	// or was issued for a different sort or order.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrVersionConflict is returned by a write when the album exists but is
+	// no longer at the version the caller expected.
+	ErrVersionConflict = errors.New("album version conflict")
 )
 
 // repo interface
+// FindByID, Update, Patch and Delete return sql.ErrNoRows when no album has
+// the given ID. Update, Patch and Delete only apply if the album is still at
+// version, returning ErrVersionConflict otherwise; a zero version skips the
+// check. Each write bumps the version by one.
 type AlbumRepository interface {
-	FindAll() ([]model.Album, error)
-	FindByID(id string) (model.Album, error)
-	Create(album model.Album) (model.Album, error)
-	Update(id string, album model.Album) (model.Album, error)
-	Delete(id string) error
-	Patch(id string, album model.Album) (model.Album, error)
+	FindAll(ctx context.Context, query model.AlbumQuery) (model.AlbumPage, error)
+	FindByID(ctx context.Context, id string) (model.Album, error)
+	Search(ctx context.Context, query string, limit int) ([]model.AlbumSearchResult, error)
+	Create(ctx context.Context, album model.Album) (model.Album, error)
+	Update(ctx context.Context, id string, album model.Album, version int64) (model.Album, error)
+	Delete(ctx context.Context, id string, version int64) error
+	Patch(ctx context.Context, id string, patch model.AlbumPatch, version int64) (model.Album, error)
 }
 
 // implementation
 type AlbumRepoImpl struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect database.Dialect
 }
 
+// albumColumns is the select list scanned by scanAlbum.
+const albumColumns = "id, title, artist, price, version, created_at, updated_at"
+
 // Create implements AlbumRepository.
-func (a *AlbumRepoImpl) Create(album model.Album) (model.Album, error) {
+func (a *AlbumRepoImpl) Create(ctx context.Context, album model.Album) (model.Album, error) {
+	// updated_at is set here because SQLite cannot default it
 	query := `
-		INSERT INTO albums (title, artist, price)
-		VALUES ($1, $2, $3)
-		RETURNING id
+		INSERT INTO albums (title, artist, price, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
 	`
 
-	err := a.db.QueryRow(
+	id, err := insertReturningID(
+		ctx,
+		a.db,
+		a.dialect,
 		query,
 		album.Title,
 		album.Artist,
 		album.Price,
-	).Scan(&album.ID)
+	)
+	if err != nil {
+		return model.Album{}, err
+	}
 
-	return album, err
+	// read back the values the database filled in
+	return a.FindByID(ctx, id)
 }
 
 // Delete implements AlbumRepository.
-func (a *AlbumRepoImpl) Delete(id string) error {
-	query := `DELETE FROM albums WHERE id = $1`
+func (a *AlbumRepoImpl) Delete(ctx context.Context, id string, version int64) error {
+	albumID, err := idArg(id)
+	if err != nil {
+		return err
+	}
+	query := `DELETE FROM albums WHERE id = ? AND ` + versionMatches
 
-	_, err := a.db.Exec(query, id)
-	return err
+	res, err := a.db.ExecContext(ctx, a.dialect.Rebind(query), albumID, versionArg(version))
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return a.missingOrConflict(ctx, albumID)
+	}
+	return nil
 }
 
 // FindAll implements AlbumRepository.
-func (a *AlbumRepoImpl) FindAll() ([]model.Album, error) {
-	query := `
-		SELECT id, title, artist, price
-		FROM albums
-	`
+// It pages with a keyset on (sort column, id) so deep pages stay cheap.
+func (a *AlbumRepoImpl) FindAll(ctx context.Context, q model.AlbumQuery) (model.AlbumPage, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	// conditions and args are appended in the order their ? placeholders appear
+	where := func(cond string, values ...any) {
+		conds = append(conds, cond)
+		args = append(args, values...)
+	}
+
+	if q.Artist != "" {
+		where(a.dialect.ILike("artist"), likePattern(q.Artist))
+	}
+	if q.Title != "" {
+		where(a.dialect.ILike("title"), likePattern(q.Title))
+	}
+	if q.MinPrice != nil {
+		where("price >= ?", *q.MinPrice)
+	}
+	if q.MaxPrice != nil {
+		where("price <= ?", *q.MaxPrice)
+	}
+	if q.UpdatedSince != nil {
+		where("updated_at >= ?", timestampArg(*q.UpdatedSince))
+	}
+
+	page := model.AlbumPage{Data: []model.Album{}}
+
+	// total ignores the cursor so it stays the same across pages
+	countQuery := "SELECT COUNT(*) FROM albums" + whereClause(conds)
+	if err := a.db.QueryRowContext(ctx, a.dialect.Rebind(countQuery), args...).Scan(&page.Total); err != nil {
+		return model.AlbumPage{}, err
+	}
+
+	column, ok := albumSortColumns[q.Sort]
+	if !ok {
+		column = "id"
+	}
+	op, dir := ">", "ASC"
+	if q.Order == "desc" {
+		op, dir = "<", "DESC"
+	}
+
+	if q.Cursor != "" {
+		cur, err := decodeCursor(q.Cursor, column, dir)
+		if err != nil {
+			return model.AlbumPage{}, err
+		}
+		if column == "id" {
+			where("id "+op+" ?", cur.ID)
+		} else {
+			where(fmt.Sprintf("(%s, id) %s (?, ?)", column, op), cur.Value, cur.ID)
+		}
+	}
+
+	orderBy := column + " " + dir
+	if column != "id" {
+		orderBy += ", id " + dir
+	}
 
-	rows, err := a.db.Query(query)
+	// fetch one extra row to know whether another page exists
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM albums%s
+		ORDER BY %s
+		LIMIT ?
+	`, albumColumns, whereClause(conds), orderBy)
+	args = append(args, q.Limit+1)
+
+	rows, err := a.db.QueryContext(ctx, a.dialect.Rebind(query), args...)
 	if err != nil {
-		return nil, err
+		return model.AlbumPage{}, err
 	}
 	defer rows.Close()
 
-	var albums []model.Album
-
 	for rows.Next() {
-		var album model.Album
-		if err := rows.Scan(
-			&album.ID,
-			&album.Title,
-			&album.Artist,
-			&album.Price,
-		); err != nil {
-			return nil, err
+		album, err := scanAlbum(rows)
+		if err != nil {
+			return model.AlbumPage{}, err
 		}
 
-		albums = append(albums, album)
+		page.Data = append(page.Data, album)
+	}
+	if err := rows.Err(); err != nil {
+		return model.AlbumPage{}, err
+	}
+
+	if len(page.Data) > q.Limit {
+		page.Data = page.Data[:q.Limit]
+		page.NextCursor = encodeCursor(page.Data[q.Limit-1], column, dir)
 	}
 
-	return albums, nil
+	return page, nil
 }
 
 // FindByID implements AlbumRepository.
-func (a *AlbumRepoImpl) FindByID(id string) (model.Album, error) {
+func (a *AlbumRepoImpl) FindByID(ctx context.Context, id string) (model.Album, error) {
+	albumID, err := idArg(id)
+	if err != nil {
+		return model.Album{}, err
+	}
+	return a.findByID(ctx, albumID)
+}
+
+func (a *AlbumRepoImpl) findByID(ctx context.Context, id int64) (model.Album, error) {
 	query := `
-		SELECT id, title, artist, price
+		SELECT ` + albumColumns + `
 		FROM albums
-		WHERE id = $1
+		WHERE id = ?
 	`
 
-	var album model.Album
-
-	err := a.db.QueryRow(query, id).Scan(
-		&album.ID,
-		&album.Title,
-		&album.Artist,
-		&album.Price,
-	)
-
-	if err == sql.ErrNoRows {
-		return model.Album{}, err
-	}
-
-	return album, err
+	return scanAlbum(a.db.QueryRowContext(ctx, a.dialect.Rebind(query), id))
 }
 
 // Update implements AlbumRepository.
-func (a *AlbumRepoImpl) Update(id string, album model.Album) (model.Album, error) {
+func (a *AlbumRepoImpl) Update(ctx context.Context, id string, album model.Album, version int64) (model.Album, error) {
+	albumID, err := idArg(id)
+	if err != nil {
+		return model.Album{}, err
+	}
 	query := `
 		UPDATE albums
-		SET title = $1,
-		    artist = $2,
-		    price = $3
-		WHERE id = $4
-		RETURNING id, title, artist, price
+		SET title = ?,
+		    artist = ?,
+		    price = ?,
+		    version = version + 1,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND ` + versionMatches + `
 	`
 
-	err := a.db.QueryRow(
+	return a.updateAndFetch(
+		ctx,
+		albumID,
 		query,
 		album.Title,
 		album.Artist,
 		album.Price,
-		id,
-	).Scan(
-		&album.ID,
-		&album.Title,
-		&album.Artist,
-		&album.Price,
+		albumID,
+		versionArg(version),
 	)
-
-	return album, err
 }
 
 // Patch implements AlbumRepository.
-func (a *AlbumRepoImpl) Patch(id string, album model.Album) (model.Album, error) {
-	query := `
+// Only the fields set in patch are written, so any of them may be set to a
+// zero value.
+func (a *AlbumRepoImpl) Patch(ctx context.Context, id string, patch model.AlbumPatch, version int64) (model.Album, error) {
+	albumID, err := idArg(id)
+	if err != nil {
+		return model.Album{}, err
+	}
+
+	var (
+		sets []string
+		args []any
+	)
+	set := func(column string, value any) {
+		sets = append(sets, column+" = ?")
+		args = append(args, value)
+	}
+
+	if patch.Title != nil {
+		set("title", *patch.Title)
+	}
+	if patch.Artist != nil {
+		set("artist", *patch.Artist)
+	}
+	if patch.Price != nil {
+		set("price", *patch.Price)
+	}
+	sets = append(sets, "version = version + 1", "updated_at = CURRENT_TIMESTAMP")
+
+	query := fmt.Sprintf(`
 		UPDATE albums
-		SET
-			title  = COALESCE(NULLIF($1, ''), title),
-			artist = COALESCE(NULLIF($2, ''), artist),
-			price  = COALESCE(NULLIF($3, 0), price)
-		WHERE id = $4
-		RETURNING id, title, artist, price
-	`
+		SET %s
+		WHERE id = ? AND %s
+	`, strings.Join(sets, ", "), versionMatches)
+	args = append(args, albumID, versionArg(version))
 
-	err := a.db.QueryRow(
-		query,
-		album.Title,
-		album.Artist,
-		album.Price,
-		id,
-	).Scan(
+	return a.updateAndFetch(ctx, albumID, query, args...)
+}
+
+// updateAndFetch runs an UPDATE of a single album and returns the stored row.
+// If no row matched it returns sql.ErrNoRows when there is no album with that
+// id and ErrVersionConflict when there is. It uses RETURNING where the dialect
+// supports it and re-reads the row otherwise.
+func (a *AlbumRepoImpl) updateAndFetch(ctx context.Context, id int64, query string, args ...any) (model.Album, error) {
+	if a.dialect.SupportsReturning() {
+		query += "RETURNING " + albumColumns
+		album, err := scanAlbum(a.db.QueryRowContext(ctx, a.dialect.Rebind(query), args...))
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Album{}, a.missingOrConflict(ctx, id)
+		}
+		return album, err
+	}
+
+	res, err := a.db.ExecContext(ctx, a.dialect.Rebind(query), args...)
+	if err != nil {
+		return model.Album{}, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return model.Album{}, err
+	}
+	if n == 0 {
+		return model.Album{}, a.missingOrConflict(ctx, id)
+	}
+	return a.findByID(ctx, id)
+}
+
+// versionMatches is the condition conditional writes add to their WHERE
+// clause. Its argument comes from versionArg.
+const versionMatches = "version = COALESCE(?, version)"
+
+// versionArg binds version for versionMatches; NULL matches any version.
+func versionArg(version int64) any {
+	if version == 0 {
+		return nil
+	}
+	return version
+}
+
+// timestampArg binds t for comparison with a TIMESTAMP column. Every
+// session runs in UTC (database.Open pins the PostgreSQL and MySQL session
+// zone), so the columns read as UTC; SQLite compares them as text, so t is
+// sent in the same "YYYY-MM-DD HH:MM:SS" form CURRENT_TIMESTAMP produces.
+func timestampArg(t time.Time) string {
+	return t.UTC().Format(time.DateTime)
+}
+
+// missingOrConflict tells apart the two reasons a conditional write on id
+// matched no row: sql.ErrNoRows if the album does not exist, otherwise
+// ErrVersionConflict.
+func (a *AlbumRepoImpl) missingOrConflict(ctx context.Context, id int64) error {
+	query := `SELECT 1 FROM albums WHERE id = ?`
+
+	var exists int
+	if err := a.db.QueryRowContext(ctx, a.dialect.Rebind(query), id).Scan(&exists); err != nil {
+		return err
+	}
+	return ErrVersionConflict
+}
+
+func NewAlbumRepoImpl(db *sql.DB, dialect database.Dialect) AlbumRepository {
+	return &AlbumRepoImpl{db: db, dialect: dialect}
+}
+
+// scanAlbum reads one row selected with albumColumns.
+func scanAlbum(row interface{ Scan(dest ...any) error }) (model.Album, error) {
+	var album model.Album
+
+	err := row.Scan(
 		&album.ID,
 		&album.Title,
 		&album.Artist,
 		&album.Price,
+		&album.Version,
+		&album.CreatedAt,
+		&album.UpdatedAt,
 	)
+	if err != nil {
+		return model.Album{}, err
+	}
+
+	return album, nil
+}
+
+// albumSortColumns whitelists the columns clients may sort by.
+var albumSortColumns = map[string]string{
+	"id":     "id",
+	"title":  "title",
+	"artist": "artist",
+	"price":  "price",
+}
 
-	return album, err
+// albumCursor is the last row of a page: its sort value and id, and the sort
+// column and direction the page was listed in. A cursor only makes sense in
+// the listing that issued it.
+type albumCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v,omitempty"`
+	ID    string `json:"id"`
 }
-func NewAlbumRepoImpl(db *sql.DB) AlbumRepository {
-	return &AlbumRepoImpl{db: db}
+
+func encodeCursor(album model.Album, column, dir string) string {
+	cur := albumCursor{Sort: column, Order: dir, ID: album.ID}
+	switch column {
+	case "title":
+		cur.Value = album.Title
+	case "artist":
+		cur.Value = album.Artist
+	case "price":
+		cur.Value = strconv.FormatFloat(album.Price, 'f', -1, 64)
+	}
+
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes s, checking it was issued for column and dir.
+func decodeCursor(s, column, dir string) (albumCursor, error) {
+	var cur albumCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cur); err != nil || cur.ID == "" {
+		return cur, ErrInvalidCursor
+	}
+	if cur.Sort != column || cur.Order != dir {
+		return cur, ErrInvalidCursor
+	}
+	if _, err := strconv.ParseInt(cur.ID, 10, 64); err != nil {
+		return cur, ErrInvalidCursor
+	}
+	if column == "price" {
+		if _, err := strconv.ParseFloat(cur.Value, 64); err != nil {
+			return cur, ErrInvalidCursor
+		}
+	}
+	return cur, nil
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "\n\t\tWHERE " + strings.Join(conds, " AND ")
+}
+
+// likePattern builds a substring match, escaping LIKE wildcards in s.
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
 }
@@ -0,0 +1,340 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"example/go-web-gin/database"
+	"example/go-web-gin/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+var testAlbums = []model.Album{
+	{Title: "Blue Train", Artist: "John Coltrane", Price: 56.99},
+	{Title: "Jeru", Artist: "Gerry Mulligan", Price: 17.99},
+	{Title: "Sarah Vaughan and Clifford Brown", Artist: "Sarah Vaughan", Price: 39.99},
+	{Title: "Kind of Blue", Artist: "Miles Davis", Price: 29.99},
+	{Title: "100%_Pure", Artist: `X\Y`, Price: 9.99},
+}
+
+// seedAlbums creates testAlbums and returns them as stored.
+func seedAlbums(t *testing.T, repo AlbumRepository) []model.Album {
+	t.Helper()
+	stored := make([]model.Album, 0, len(testAlbums))
+	for _, album := range testAlbums {
+		created, err := repo.Create(context.Background(), album)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored = append(stored, created)
+	}
+	return stored
+}
+
+func TestAlbumCreateAndFind(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+
+		created, err := repo.Create(ctx, testAlbums[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == "" || created.Version != 1 || created.CreatedAt.IsZero() || !created.UpdatedAt.Equal(created.CreatedAt) {
+			t.Fatalf("Create returned %+v, want an id, version 1 and equal timestamps", created)
+		}
+
+		found, err := repo.FindByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Title != created.Title || found.Artist != created.Artist || found.Price != created.Price || found.Version != 1 {
+			t.Errorf("FindByID = %+v, want %+v", found, created)
+		}
+
+		if _, err := repo.FindByID(ctx, "999999"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("FindByID of a missing album: got %v, want sql.ErrNoRows", err)
+		}
+	})
+}
+
+func TestAlbumBadIDIsNotFound(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+		seedAlbums(t, repo)
+		title := "Giant Steps"
+
+		// PostgreSQL would fail these with invalid_text_representation and
+		// numeric_value_out_of_range
+		for _, id := range []string{"abc", "1.5", "99999999999999999999", ""} {
+			if _, err := repo.FindByID(ctx, id); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("FindByID(%q): got %v, want sql.ErrNoRows", id, err)
+			}
+			if _, err := repo.Update(ctx, id, testAlbums[0], 0); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("Update(%q): got %v, want sql.ErrNoRows", id, err)
+			}
+			if _, err := repo.Patch(ctx, id, model.AlbumPatch{Title: &title}, 0); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("Patch(%q): got %v, want sql.ErrNoRows", id, err)
+			}
+			if err := repo.Delete(ctx, id, 0); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("Delete(%q): got %v, want sql.ErrNoRows", id, err)
+			}
+		}
+	})
+}
+
+func TestAlbumUpdate(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+		album := seedAlbums(t, repo)[0]
+
+		change := model.Album{Title: "Giant Steps", Artist: "John Coltrane", Price: 19.99}
+		updated, err := repo.Update(ctx, album.ID, change, album.Version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Title != change.Title || updated.Price != change.Price || updated.Version != album.Version+1 {
+			t.Errorf("Update returned %+v, want %+v at version %d", updated, change, album.Version+1)
+		}
+
+		if _, err := repo.Update(ctx, album.ID, change, album.Version); !errors.Is(err, ErrVersionConflict) {
+			t.Errorf("Update with a stale version: got %v, want ErrVersionConflict", err)
+		}
+		if _, err := repo.Update(ctx, album.ID, change, 0); err != nil {
+			t.Errorf("Update with any version: %v", err)
+		}
+		if _, err := repo.Update(ctx, "999999", change, 0); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Update of a missing album: got %v, want sql.ErrNoRows", err)
+		}
+	})
+}
+
+func TestAlbumPatch(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+		album := seedAlbums(t, repo)[0]
+
+		title := "Giant Steps"
+		patched, err := repo.Patch(ctx, album.ID, model.AlbumPatch{Title: &title}, album.Version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if patched.Title != title || patched.Artist != album.Artist || patched.Price != album.Price {
+			t.Errorf("Patch returned %+v, want only the title changed from %+v", patched, album)
+		}
+		if patched.Version != album.Version+1 {
+			t.Errorf("Patch returned version %d, want %d", patched.Version, album.Version+1)
+		}
+
+		// fields Patch does not set may hold any value, including empty ones
+		artist := ""
+		if _, err := repo.Patch(ctx, album.ID, model.AlbumPatch{Artist: &artist}, 0); err != nil {
+			t.Errorf("Patch to an empty artist: %v", err)
+		}
+
+		if _, err := repo.Patch(ctx, album.ID, model.AlbumPatch{Title: &title}, album.Version); !errors.Is(err, ErrVersionConflict) {
+			t.Errorf("Patch with a stale version: got %v, want ErrVersionConflict", err)
+		}
+		if _, err := repo.Patch(ctx, "999999", model.AlbumPatch{Title: &title}, 0); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Patch of a missing album: got %v, want sql.ErrNoRows", err)
+		}
+	})
+}
+
+func TestAlbumDelete(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+		album := seedAlbums(t, repo)[0]
+
+		if err := repo.Delete(ctx, album.ID, album.Version+1); !errors.Is(err, ErrVersionConflict) {
+			t.Errorf("Delete with a stale version: got %v, want ErrVersionConflict", err)
+		}
+		if err := repo.Delete(ctx, album.ID, album.Version); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Delete(ctx, album.ID, 0); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("second Delete: got %v, want sql.ErrNoRows", err)
+		}
+	})
+}
+
+func TestAlbumFindAllFilters(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+		seedAlbums(t, repo)
+
+		minPrice, maxPrice := 15.0, 40.0
+		tests := []struct {
+			name  string
+			query model.AlbumQuery
+			want  []string
+		}{
+			{"artist is case-insensitive", model.AlbumQuery{Artist: "SARAH"}, []string{"Sarah Vaughan and Clifford Brown"}},
+			{"title substring", model.AlbumQuery{Title: "blue"}, []string{"Blue Train", "Kind of Blue"}},
+			// % and _ in the filter are literal, not wildcards
+			{"escaped wildcards", model.AlbumQuery{Title: "0%_p"}, []string{"100%_Pure"}},
+			{"escaped backslash", model.AlbumQuery{Artist: `x\y`}, []string{"100%_Pure"}},
+			{"price range", model.AlbumQuery{MinPrice: &minPrice, MaxPrice: &maxPrice}, []string{"Jeru", "Sarah Vaughan and Clifford Brown", "Kind of Blue"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.query.Limit = 10
+				page, err := repo.FindAll(ctx, tt.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := albumTitles(page.Data); !slices.Equal(got, tt.want) {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+				if page.Total != len(tt.want) {
+					t.Errorf("total %d, want %d", page.Total, len(tt.want))
+				}
+			})
+		}
+	})
+}
+
+func TestAlbumFindAllPages(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+		seedAlbums(t, repo)
+
+		query := model.AlbumQuery{Limit: 2, Sort: "price", Order: "desc"}
+		var titles []string
+		for pages := 0; ; pages++ {
+			if pages > len(testAlbums) {
+				t.Fatal("paging did not end")
+			}
+			page, err := repo.FindAll(ctx, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if page.Total != len(testAlbums) {
+				t.Errorf("total %d, want %d", page.Total, len(testAlbums))
+			}
+			titles = append(titles, albumTitles(page.Data)...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+
+		want := []string{"Blue Train", "Sarah Vaughan and Clifford Brown", "Kind of Blue", "Jeru", "100%_Pure"}
+		if !slices.Equal(titles, want) {
+			t.Errorf("pages hold %q, want %q", titles, want)
+		}
+	})
+}
+
+func TestAlbumFindAllRejectsCursorFromOtherSort(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+		seedAlbums(t, repo)
+
+		page, err := repo.FindAll(ctx, model.AlbumQuery{Limit: 2, Sort: "price", Order: "asc"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.NextCursor == "" {
+			t.Fatal("first page has no next cursor")
+		}
+
+		for _, q := range []model.AlbumQuery{
+			{Sort: "title", Order: "asc"},
+			{Sort: "price", Order: "desc"},
+			{Sort: "id", Order: "asc"},
+		} {
+			q.Limit, q.Cursor = 2, page.NextCursor
+			if _, err := repo.FindAll(ctx, q); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("price asc cursor with sort=%s order=%s: got %v, want ErrInvalidCursor", q.Sort, q.Order, err)
+			}
+		}
+
+		for _, cursor := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+			if _, err := repo.FindAll(ctx, model.AlbumQuery{Limit: 2, Cursor: cursor}); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("cursor %q: got %v, want ErrInvalidCursor", cursor, err)
+			}
+		}
+	})
+}
+
+func TestDecodeCursorChecksPriceValue(t *testing.T) {
+	cursor := encodeCursor(model.Album{ID: "1", Title: "Jeru"}, "title", "ASC")
+	if _, err := decodeCursor(cursor, "title", "ASC"); err != nil {
+		t.Fatalf("decode own cursor: %v", err)
+	}
+
+	// a hand-made cursor with a price that is not a number
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"price","o":"ASC","v":"Jeru","id":"1"}`))
+	if _, err := decodeCursor(forged, "price", "ASC"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("non-numeric price cursor: got %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestAlbumFindAllUpdatedSince(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+		album := seedAlbums(t, repo)[0]
+
+		// updated_at is read back in UTC and compares equal to what it
+		// was written as, whatever the zone of the caller's time
+		if album.UpdatedAt.Location() != time.UTC {
+			t.Errorf("updated_at is in %s, want UTC", album.UpdatedAt.Location())
+		}
+		since := album.UpdatedAt.In(time.FixedZone("UTC-5", -5*60*60))
+		page, err := repo.FindAll(ctx, model.AlbumQuery{Limit: 10, UpdatedSince: &since})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Total != len(testAlbums) {
+			t.Errorf("updated since %s: total %d, want %d", since, page.Total, len(testAlbums))
+		}
+
+		later := album.UpdatedAt.Add(time.Hour)
+		page, err = repo.FindAll(ctx, model.AlbumQuery{Limit: 10, UpdatedSince: &later})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Total != 0 {
+			t.Errorf("updated since %s: total %d, want 0", later, page.Total)
+		}
+	})
+}
+
+func TestAlbumSearch(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		repo := NewAlbumRepoImpl(db, dialect)
+		seedAlbums(t, repo)
+
+		results, err := repo.Search(context.Background(), "coltrane", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].Title != "Blue Train" {
+			t.Fatalf("Search returned %+v, want Blue Train", results)
+		}
+		if results[0].CreatedAt.IsZero() {
+			t.Error("search result has no created_at")
+		}
+	})
+}
+
+func albumTitles(albums []model.Album) []string {
+	titles := make([]string, 0, len(albums))
+	for _, album := range albums {
+		titles = append(titles, album.Title)
+	}
+	return titles
+}
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"example/go-web-gin/database"
+	"example/go-web-gin/model"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Search implements AlbumRepository.
+// It ranks full-text matches first and falls back to a fuzzier match when
+// the text search finds nothing, so typos and partial words still return
+// results. Only PostgreSQL has trigram similarity; other dialects fall back
+// to substring matching.
+func (a *AlbumRepoImpl) Search(ctx context.Context, q string, limit int) ([]model.AlbumSearchResult, error) {
+	var (
+		results []model.AlbumSearchResult
+		err     error
+	)
+
+	switch a.dialect.Name() {
+	case database.POSTGRES:
+		results, err = a.searchFullText(ctx, q, limit)
+	case database.MYSQL:
+		results, err = a.searchMySQL(ctx, q, limit)
+	}
+	if err != nil || len(results) > 0 {
+		return results, err
+	}
+
+	if a.dialect.Name() == database.POSTGRES {
+		return a.searchTrigram(ctx, q, limit)
+	}
+	return a.searchSubstring(ctx, q, limit)
+}
+
+// searchFullText highlights with ts_headline, which copies the text into its
+// output as is, so the columns are HTML-escaped before they reach it.
+func (a *AlbumRepoImpl) searchFullText(ctx context.Context, q string, limit int) ([]model.AlbumSearchResult, error) {
+	query := `
+		SELECT id, title, artist, price, created_at, updated_at,
+		       ts_rank(search_vector, query) AS rank,
+		       ts_headline('simple', ` + escapeHTMLSQL("title") + `, query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+		       ts_headline('simple', ` + escapeHTMLSQL("artist") + `, query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+		FROM albums, websearch_to_tsquery('simple', $1) AS query
+		WHERE search_vector @@ query
+		ORDER BY rank DESC, id
+		LIMIT $2
+	`
+
+	rows, err := a.db.QueryContext(ctx, query, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []model.AlbumSearchResult{}
+	for rows.Next() {
+		result := model.AlbumSearchResult{Match: "fulltext", Highlight: &model.AlbumHighlight{}}
+		if err := rows.Scan(
+			&result.ID,
+			&result.Title,
+			&result.Artist,
+			&result.Price,
+			&result.CreatedAt,
+			&result.UpdatedAt,
+			&result.Rank,
+			&result.Highlight.Title,
+			&result.Highlight.Artist,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+func (a *AlbumRepoImpl) searchTrigram(ctx context.Context, q string, limit int) ([]model.AlbumSearchResult, error) {
+	query := `
+		SELECT id, title, artist, price, created_at, updated_at,
+		       GREATEST(word_similarity($1, title), word_similarity($1, artist)) AS rank
+		FROM albums
+		WHERE $1 <% title OR $1 <% artist
+		ORDER BY rank DESC, id
+		LIMIT $2
+	`
+
+	return a.scanSearchResults(ctx, "trigram", nil, query, q, limit)
+}
+
+// searchMySQL uses the FULLTEXT index on (title, artist).
+func (a *AlbumRepoImpl) searchMySQL(ctx context.Context, q string, limit int) ([]model.AlbumSearchResult, error) {
+	query := `
+		SELECT id, title, artist, price, created_at, updated_at,
+		       MATCH(title, artist) AGAINST (? IN NATURAL LANGUAGE MODE) AS score
+		FROM albums
+		WHERE MATCH(title, artist) AGAINST (? IN NATURAL LANGUAGE MODE)
+		ORDER BY score DESC, id
+		LIMIT ?
+	`
+
+	return a.scanSearchResults(ctx, "fulltext", searchTerms(q), query, q, q, limit)
+}
+
+// searchSubstring matches albums whose title or artist contains every term.
+func (a *AlbumRepoImpl) searchSubstring(ctx context.Context, q string, limit int) ([]model.AlbumSearchResult, error) {
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		return []model.AlbumSearchResult{}, nil
+	}
+
+	var (
+		conds []string
+		args  []any
+	)
+	for _, term := range terms {
+		conds = append(conds, "("+a.dialect.ILike("title")+" OR "+a.dialect.ILike("artist")+")")
+		args = append(args, likePattern(term), likePattern(term))
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT id, title, artist, price, created_at, updated_at, 0 AS rank
+		FROM albums` + whereClause(conds) + `
+		ORDER BY id
+		LIMIT ?
+	`
+
+	return a.scanSearchResults(ctx, "substring", terms, query, args...)
+}
+
+// scanSearchResults runs a search query selecting an album and its rank.
+// When terms is non-nil the highlight is built in Go, for dialects without
+// a ts_headline equivalent.
+func (a *AlbumRepoImpl) scanSearchResults(ctx context.Context, match string, terms []string, query string, args ...any) ([]model.AlbumSearchResult, error) {
+	rows, err := a.db.QueryContext(ctx, a.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []model.AlbumSearchResult{}
+	for rows.Next() {
+		result := model.AlbumSearchResult{Match: match}
+		if err := rows.Scan(
+			&result.ID,
+			&result.Title,
+			&result.Artist,
+			&result.Price,
+			&result.CreatedAt,
+			&result.UpdatedAt,
+			&result.Rank,
+		); err != nil {
+			return nil, err
+		}
+		if terms != nil {
+			result.Highlight = &model.AlbumHighlight{
+				Title:  highlight(result.Title, terms),
+				Artist: highlight(result.Artist, terms),
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+func searchTerms(q string) []string {
+	return strings.Fields(q)
+}
+
+// htmlEscapes lists the replacements html.EscapeString makes, ampersand
+// first so the others are not escaped twice.
+var htmlEscapes = [][2]string{
+	{"&", "&amp;"},
+	{"'", "&#39;"},
+	{"<", "&lt;"},
+	{">", "&gt;"},
+	{`"`, "&#34;"},
+}
+
+// escapeHTMLSQL returns an SQL expression escaping column the way
+// html.EscapeString does.
+func escapeHTMLSQL(column string) string {
+	expr := column
+	for _, e := range htmlEscapes {
+		expr = "replace(" + expr + ", '" + strings.ReplaceAll(e[0], "'", "''") + "', '" + e[1] + "')"
+	}
+	return expr
+}
+
+// highlight HTML-escapes text and wraps case-insensitive occurrences of
+// terms in <mark> tags.
+func highlight(text string, terms []string) string {
+	if len(terms) == 0 {
+		return html.EscapeString(text)
+	}
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	re := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+
+	// match on the raw text, so a term never matches inside an escape
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:m[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[m[0]:m[1]]) + "</mark>")
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
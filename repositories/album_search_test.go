@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"example/go-web-gin/database"
+	"example/go-web-gin/model"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text  string
+		terms []string
+		want  string
+	}{
+		{"Blue Train", []string{"blue"}, "<mark>Blue</mark> Train"},
+		{"Kind of Blue", []string{"kind", "BLUE"}, "<mark>Kind</mark> of <mark>Blue</mark>"},
+		{"Jeru", nil, "Jeru"},
+		// stored markup comes back as text, never as HTML
+		{"T3 <b>x</b>", []string{"x"}, "T3 &lt;b&gt;<mark>x</mark>&lt;/b&gt;"},
+		{`<img src=x onerror="alert(1)">`, nil, "&lt;img src=x onerror=&#34;alert(1)&#34;&gt;"},
+		// terms match the text, not its escaped form
+		{"Tom & Jerry", []string{"amp"}, "Tom &amp; Jerry"},
+		{"Tom & Jerry", []string{"&"}, "Tom <mark>&amp;</mark> Jerry"},
+	}
+
+	for _, tt := range tests {
+		if got := highlight(tt.text, tt.terms); got != tt.want {
+			t.Errorf("highlight(%q, %q) = %q, want %q", tt.text, tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestSearchHighlightEscapesHTML(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewAlbumRepoImpl(db, dialect)
+		if _, err := repo.Create(ctx, model.Album{Title: "T3 <b>x</b>", Artist: `O'Brien & "Co"`, Price: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		results, err := repo.Search(ctx, "x", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].Highlight == nil {
+			t.Fatalf("Search returned %+v, want one highlighted result", results)
+		}
+		if want := "T3 &lt;b&gt;<mark>x</mark>&lt;/b&gt;"; results[0].Highlight.Title != want {
+			t.Errorf("title highlight %q, want %q", results[0].Highlight.Title, want)
+		}
+		if want := "O&#39;Brien &amp; &#34;Co&#34;"; results[0].Highlight.Artist != want {
+			t.Errorf("artist highlight %q, want %q", results[0].Highlight.Artist, want)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"example/go-web-gin/config"
+	"example/go-web-gin/database"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// Repository tests run against an in-memory SQLite database, and also
+// against PostgreSQL and MySQL when these variables hold a DSN for an
+// empty, disposable database, e.g.
+//
+//	TEST_POSTGRES_DSN="host=localhost user=postgres password=postgres dbname=albums_test sslmode=disable"
+//	TEST_MYSQL_DSN="root:root@tcp(localhost:3306)/albums_test"
+//
+// Those databases are migrated and their tables emptied before each test.
+const (
+	postgresDSNEnv = "TEST_POSTGRES_DSN"
+	mysqlDSNEnv    = "TEST_MYSQL_DSN"
+)
+
+// forEachDB runs test once per available database, as a subtest named
+// after the driver.
+func forEachDB(t *testing.T, test func(t *testing.T, db *sql.DB, dialect database.Dialect)) {
+	t.Helper()
+
+	t.Run(database.SQLITE, func(t *testing.T) {
+		test(t, openSQLite(t), database.DialectFor(database.SQLITE))
+	})
+	for _, target := range []struct{ driver, env string }{
+		{database.POSTGRES, postgresDSNEnv},
+		{database.MYSQL, mysqlDSNEnv},
+	} {
+		t.Run(target.driver, func(t *testing.T) {
+			dsn := os.Getenv(target.env)
+			if dsn == "" {
+				t.Skipf("%s not set", target.env)
+			}
+			test(t, openShared(t, target.driver, dsn), database.DialectFor(target.driver))
+		})
+	}
+}
+
+// openSQLite returns a fresh in-memory SQLite database with the embedded
+// migrations applied, closed when the test ends.
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.Open(config.DBConfig{Driver: database.SQLITE, Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// the migrator is not closed, as that would close db
+	m, err := database.NewMigrator(db, database.SQLITE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrateUp(t, m)
+	return db
+}
+
+// openShared migrates the database at dsn and empties its tables.
+func openShared(t *testing.T, driver, dsn string) *sql.DB {
+	t.Helper()
+
+	if driver == database.MYSQL {
+		dsn = mysqlTestDSN(t, dsn)
+	}
+
+	// migrate on a pool of its own, which closing the migrator closes
+	migrationDB, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := database.NewMigrator(migrationDB, driver)
+	if err != nil {
+		migrationDB.Close()
+		t.Fatal(err)
+	}
+	migrateUp(t, m)
+	m.Close()
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, table := range []string{"albums", "users"} {
+		if _, err := db.Exec("DELETE FROM " + table); err != nil {
+			t.Fatalf("empty %s: %v", table, err)
+		}
+	}
+	return db
+}
+
+// mysqlTestDSN adds the options database.Open relies on to a MySQL DSN.
+func mysqlTestDSN(t *testing.T, dsn string) string {
+	t.Helper()
+
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("%s: %v", mysqlDSNEnv, err)
+	}
+	cfg.ParseTime = true
+	cfg.ClientFoundRows = true
+	cfg.Loc = time.UTC
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	cfg.Params["time_zone"] = "'+00:00'"
+	return cfg.FormatDSN()
+}
+
+func migrateUp(t *testing.T, m *migrate.Migrate) {
+	t.Helper()
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("migrate: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"example/go-web-gin/database"
+	"strconv"
+)
+
+// idArg parses id for a WHERE id = ? condition. Ids are integer columns,
+// and PostgreSQL fails a query whose id argument is not a number or is out
+// of range instead of matching no row, so such an id is reported as
+// sql.ErrNoRows before it reaches any dialect.
+func idArg(id string) (int64, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, sql.ErrNoRows
+	}
+	return n, nil
+}
+
+// insertReturningID runs an INSERT and returns the generated id, through
+// RETURNING where the dialect supports it and LastInsertId otherwise.
+func insertReturningID(ctx context.Context, db *sql.DB, dialect database.Dialect, query string, args ...any) (string, error) {
+	if dialect.SupportsReturning() {
+		var id string
+		err := db.QueryRowContext(ctx, dialect.Rebind(query+"RETURNING id"), args...).Scan(&id)
+		return id, err
+	}
+
+	res, err := db.ExecContext(ctx, dialect.Rebind(query), args...)
+	if err != nil {
+		return "", err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"example/go-web-gin/database"
+	"example/go-web-gin/model"
+)
+
+// ErrEmailTaken is returned when creating a user whose email is already registered.
+var ErrEmailTaken = errors.New("email already registered")
+
+// repo interface
+type UserRepository interface {
+	Create(ctx context.Context, user model.User) (model.User, error)
+	FindByEmail(ctx context.Context, email string) (model.User, error)
+	FindByID(ctx context.Context, id string) (model.User, error)
+}
+
+// implementation
+type UserRepoImpl struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+// Create implements UserRepository.
+func (u *UserRepoImpl) Create(ctx context.Context, user model.User) (model.User, error) {
+	query := `
+		INSERT INTO users (email, password_hash, name, role)
+		VALUES (?, ?, ?, ?)
+	`
+
+	id, err := insertReturningID(
+		ctx,
+		u.db,
+		u.dialect,
+		query,
+		user.Email,
+		user.PasswordHash,
+		user.Name,
+		user.Role,
+	)
+	if u.dialect.IsUniqueViolation(err) {
+		return model.User{}, ErrEmailTaken
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	user.ID = id
+	return user, nil
+}
+
+// FindByEmail implements UserRepository.
+func (u *UserRepoImpl) FindByEmail(ctx context.Context, email string) (model.User, error) {
+	query := `
+		SELECT id, email, name, role, password_hash
+		FROM users
+		WHERE email = ?
+	`
+
+	return u.scanUser(u.db.QueryRowContext(ctx, u.dialect.Rebind(query), email))
+}
+
+// FindByID implements UserRepository.
+func (u *UserRepoImpl) FindByID(ctx context.Context, id string) (model.User, error) {
+	userID, err := idArg(id)
+	if err != nil {
+		return model.User{}, err
+	}
+	query := `
+		SELECT id, email, name, role, password_hash
+		FROM users
+		WHERE id = ?
+	`
+
+	return u.scanUser(u.db.QueryRowContext(ctx, u.dialect.Rebind(query), userID))
+}
+
+func (u *UserRepoImpl) scanUser(row *sql.Row) (model.User, error) {
+	var user model.User
+
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Name,
+		&user.Role,
+		&user.PasswordHash,
+	)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+func NewUserRepoImpl(db *sql.DB, dialect database.Dialect) UserRepository {
+	return &UserRepoImpl{db: db, dialect: dialect}
+}
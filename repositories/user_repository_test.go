@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"example/go-web-gin/database"
+	"example/go-web-gin/model"
+	"testing"
+)
+
+func TestUserCreateRejectsDuplicateEmail(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sql.DB, dialect database.Dialect) {
+		ctx := context.Background()
+		repo := NewUserRepoImpl(db, dialect)
+		user := model.User{Email: "jane@example.com", Name: "Jane", Role: model.RoleViewer, PasswordHash: "x"}
+
+		created, err := repo.Create(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found, err := repo.FindByEmail(ctx, user.Email)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.ID != created.ID || found.Role != model.RoleViewer {
+			t.Errorf("FindByEmail = %+v, want %+v", found, created)
+		}
+
+		if _, err := repo.Create(ctx, user); !errors.Is(err, ErrEmailTaken) {
+			t.Errorf("second Create: got %v, want ErrEmailTaken", err)
+		}
+		if _, err := repo.FindByID(ctx, "abc"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("FindByID of a non-numeric id: got %v, want sql.ErrNoRows", err)
+		}
+	})
+}
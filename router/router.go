@@ -1,12 +1,18 @@
 package router
 
 import (
+	"example/go-web-gin/apperr"
+	"example/go-web-gin/cache"
 	"example/go-web-gin/config"
 	"example/go-web-gin/database"
 	"example/go-web-gin/handler"
+	"example/go-web-gin/metrics"
 	"example/go-web-gin/middleware"
+	"example/go-web-gin/model"
 	"example/go-web-gin/repositories"
 	"example/go-web-gin/service"
+	"fmt"
+	"log/slog"
 
 	"example/go-web-gin/docs"
 
@@ -16,44 +22,109 @@ import (
 )
 
 // RegisterRoutes registers all application routes on the provided engine.
-func RegisterRoutes(r *gin.Engine) {
+// database.ConnectDB must have succeeded first.
+func RegisterRoutes(r *gin.Engine, logger *slog.Logger) error {
 
 	// configure swagger info so UI calls the correct server and base path
 	docs.SwaggerInfo.BasePath = "/api/v1"
-	docs.SwaggerInfo.Host = "localhost:8080"
+	cfg := config.AppConfig
+	docs.SwaggerInfo.Host = "localhost:" + cfg.HTTP.Port
 	docs.SwaggerInfo.Schemes = []string{"http"}
 
-	database.ConnectDB()
-	config.ExampleClient()
-	repo := repositories.NewAlbumRepoImpl(database.DB)
-	albumService := service.NewAlbumService(repo)
+	albumCache, tokenStore, err := newCaches(cfg.Cache, logger)
+	if err != nil {
+		return err
+	}
+	repo := repositories.NewAlbumRepoImpl(database.DB, database.SQLDialect)
+	albumService := service.NewAlbumService(repo, albumCache, cfg.Cache.TTL, logger)
 	albumHandler := handler.NewAlbumHandler(albumService)
 
-	authService := service.NewAuthService([]byte(config.AppConfig.JWTSecret))
+	userRepo := repositories.NewUserRepoImpl(database.DB, database.SQLDialect)
+	ttls := service.TokenTTLs{Access: cfg.JWT.AccessTokenTTL, Refresh: cfg.JWT.RefreshTokenTTL}
+	authService := service.NewAuthService([]byte(cfg.JWT.Secret.Reveal()), ttls, userRepo, tokenStore, logger)
 	authHandler := handler.NewAuthHandler(authService)
+	healthHandler := handler.NewHealthHandler(database.DB, config.RedisClient, cfg.HTTP.HealthCheckTimeout)
+
+	// probes and metrics for the orchestrator, outside the versioned API
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+	metrics.RegisterDB(database.DB, cfg.DB.Driver)
+	r.GET("/metrics", metrics.Handler())
+
 	v1 := r.Group("/api/v1")
 	{
 		// 🔓 Public routes
+		v1.POST("/auth/register", authHandler.Register)
 		v1.POST("/auth/login", authHandler.Login)
+		v1.POST("/auth/refresh", authHandler.Refresh)
 
 		// 🔒 Protected routes
+		requireAuth := middleware.JWTAuth(authService)
+		v1.POST("/auth/logout", requireAuth, authHandler.Logout)
+
+		admin := v1.Group("/admin")
+		admin.Use(requireAuth, middleware.RequirePermission(model.PermUsersManage))
+		{
+			admin.POST("/users/:id/revoke-tokens", authHandler.RevokeUserTokens)
+		}
+
 		protected := v1.Group("/hello")
-		protected.Use(middleware.JWTAuth(authService))
+		protected.Use(requireAuth)
 		{
 			protected.GET("/protected", func(c *gin.Context) {
 				c.JSON(200, gin.H{"message": "You have accessed a protected route"})
 			})
 		}
+		// 🔓 album reads are public, 🔒 writes need a role with permission
+		canWrite := middleware.RequirePermission(model.PermAlbumsWrite)
+		canDelete := middleware.RequirePermission(model.PermAlbumsDelete)
 		albums := v1.Group("/albums")
 		{
 			albums.GET("/", albumHandler.GetAllAlbums)
-			albums.POST("/", albumHandler.PostAlbum)
+			albums.POST("/", requireAuth, canWrite, albumHandler.PostAlbum)
+			albums.GET("/search", albumHandler.SearchAlbums)
 			albums.GET("/:id", albumHandler.GetAlbumByID)
-			albums.PUT("/:id", albumHandler.UpdateAlbum)
-			albums.DELETE("/:id", albumHandler.DeleteAlbum)
-			albums.PATCH("/:id", albumHandler.PatchAlbum)
+			albums.PUT("/:id", requireAuth, canWrite, albumHandler.UpdateAlbum)
+			albums.DELETE("/:id", requireAuth, canDelete, albumHandler.DeleteAlbum)
+			albums.PATCH("/:id", requireAuth, canWrite, albumHandler.PatchAlbum)
 		}
 	}
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
+	r.NoRoute(func(c *gin.Context) {
+		c.Error(apperr.NotFound("no route matches " + c.Request.URL.Path))
+	})
+	return nil
+}
+
+// newCaches picks the album cache and the auth token store from
+// CACHE_DRIVER, which Config.Validate has checked. Without Redis, tokens
+// live in an unbounded in-memory store because evicting a denylist entry
+// would un-revoke a token.
+//
+// Token state kept in memory is not shared between replicas, so a logout or
+// revocation would only hold on the replica that handled it. When the redis
+// driver cannot reach Redis, newCaches therefore fails unless
+// CACHE_ALLOW_MEMORY_TOKENS opts in to that.
+func newCaches(cfg config.CacheConfig, logger *slog.Logger) (albumCache, tokenStore cache.Cache, err error) {
+	driver := cfg.Driver
+	if driver == cache.REDIS {
+		if err := config.ConnectRedis(); err != nil {
+			if !cfg.AllowMemoryTokens {
+				return nil, nil, fmt.Errorf("redis unavailable, and auth tokens need it unless CACHE_ALLOW_MEMORY_TOKENS is set: %w", err)
+			}
+			logger.Warn("redis unavailable, falling back to in-memory cache and token store", "error", err)
+			driver = cache.MEMORY
+		}
+	}
+
+	switch driver {
+	case cache.REDIS:
+		store := cache.NewRedis(config.RedisClient)
+		return store, store, nil
+	case cache.NONE:
+		return cache.NewNoop(), cache.NewMemory(0), nil
+	default:
+		return cache.NewMemory(cfg.Size), cache.NewMemory(0), nil
+	}
 }
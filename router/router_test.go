@@ -0,0 +1,30 @@
+package router
+
+import (
+	"example/go-web-gin/cache"
+	"example/go-web-gin/config"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestNewCachesWithoutRedis(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	config.AppConfig = config.Default()
+	// nothing listens on port 1
+	config.AppConfig.Redis.Addr = "127.0.0.1:1"
+
+	cfg := config.CacheConfig{Driver: cache.REDIS, Size: 10}
+	if _, _, err := newCaches(cfg, logger); err == nil {
+		t.Fatal("redis driver started without Redis, want an error so tokens are not kept per process")
+	}
+
+	cfg.AllowMemoryTokens = true
+	albumCache, tokenStore, err := newCaches(cfg, logger)
+	if err != nil {
+		t.Fatalf("with AllowMemoryTokens: %v", err)
+	}
+	if albumCache == nil || tokenStore == nil {
+		t.Fatal("with AllowMemoryTokens: want in-memory caches")
+	}
+}
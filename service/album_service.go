@@ -1,95 +1,285 @@
 package service
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
-	"example/go-web-gin/config"
+	"errors"
+	"example/go-web-gin/apperr"
+	"example/go-web-gin/cache"
+	"example/go-web-gin/metrics"
 	"example/go-web-gin/model"
 	"example/go-web-gin/repositories"
-	"log"
+	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// AlbumService caches single-album reads. Every method that changes an album
+// invalidates its cache entry after the database write succeeds, so the next
+// GetAlbumByID reloads it from the database.
 type AlbumService struct {
-	repo repositories.AlbumRepository
+	repo  repositories.AlbumRepository
+	cache cache.Cache
+	// cacheTTL is how long an album stays cached
+	cacheTTL time.Duration
+	logger   *slog.Logger
 }
 
-func NewAlbumService(repo repositories.AlbumRepository) *AlbumService {
-	return &AlbumService{repo: repo}
+func albumCacheKey(id string) string {
+	return "album:" + id
 }
 
-// GetAllAlbums retrieves all albums from the repository
-func (s *AlbumService) GetAllAlbums() ([]model.Album, error) {
-	return s.repo.FindAll()
+// canonicalAlbumID parses a client-supplied album id. The database matches
+// "02" and "2" to the same row, so cache keys must only ever be built from
+// the canonical form this returns. An id that is not a positive integer
+// matches no album.
+func canonicalAlbumID(id string) (string, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || n <= 0 {
+		return "", apperr.NotFound("album not found")
+	}
+	return strconv.FormatInt(n, 10), nil
+}
+
+// cachedAlbum is how an album is stored in the cache. Unlike the API
+// encoding it keeps the version, which the ETag is built from.
+type cachedAlbum struct {
+	model.Album
+	Version int64 `json:"version"`
+}
+
+func newCachedAlbum(album model.Album) cachedAlbum {
+	return cachedAlbum{Album: album, Version: album.Version}
+}
+
+func (e cachedAlbum) album() model.Album {
+	album := e.Album
+	album.Version = e.Version
+	return album
+}
+
+func NewAlbumService(repo repositories.AlbumRepository, cache cache.Cache, cacheTTL time.Duration, logger *slog.Logger) *AlbumService {
+	return &AlbumService{repo: repo, cache: cache, cacheTTL: cacheTTL, logger: logger}
+}
+
+const (
+	defaultAlbumPageSize = 20
+	defaultAlbumSort     = "id"
+	defaultAlbumOrder    = "asc"
+)
+
+// GetAllAlbums retrieves one page of albums matching the query
+func (s *AlbumService) GetAllAlbums(ctx context.Context, query model.AlbumQuery) (model.AlbumPage, error) {
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return model.AlbumPage{}, apperr.Validation("invalid price range",
+			apperr.FieldError{Field: "min_price", Message: "must not be greater than max_price"},
+		)
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultAlbumPageSize
+	}
+	if query.Sort == "" {
+		query.Sort = defaultAlbumSort
+	}
+	if query.Order == "" {
+		query.Order = defaultAlbumOrder
+	}
+	page, err := s.repo.FindAll(ctx, query)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return page, apperr.Validation("invalid cursor",
+			apperr.FieldError{Field: "cursor", Message: "is not a cursor returned by this endpoint for this sort and order"},
+		)
+	}
+	return page, err
+}
+
+const defaultAlbumSearchLimit = 20
+
+// SearchAlbums runs a ranked full-text search over album titles and artists
+func (s *AlbumService) SearchAlbums(ctx context.Context, query model.AlbumSearchQuery) ([]model.AlbumSearchResult, error) {
+	if query.Limit == 0 {
+		query.Limit = defaultAlbumSearchLimit
+	}
+	return s.repo.Search(ctx, strings.TrimSpace(query.Q), query.Limit)
 }
 
 // GetAlbumByID retrieves a single album by its ID
-func (s *AlbumService) GetAlbumByID(id string) (model.Album, error) {
-	cacheKey := "album:" + id
-	// 1️⃣ Try cache
-	cached, err := config.RedisClient.Get(config.Ctx, cacheKey).Result()
+func (s *AlbumService) GetAlbumByID(ctx context.Context, id string) (model.Album, error) {
+	id, err := canonicalAlbumID(id)
+	if err != nil {
+		return model.Album{}, err
+	}
+	cacheKey := albumCacheKey(id)
+	// try the cache first
+	cached, err := s.cache.Get(ctx, cacheKey)
 	if err == nil {
-		log.Println("✅ Cache hit for album ID:", id)
-		var album model.Album
-		json.Unmarshal([]byte(cached), &album)
-		return album, nil
+		var entry cachedAlbum
+		if err := json.Unmarshal(cached, &entry); err == nil {
+			s.logger.DebugContext(ctx, "album cache hit", "album_id", id)
+			metrics.AlbumCacheRequests.WithLabelValues("hit").Inc()
+			return entry.album(), nil
+		}
+	} else if err != cache.ErrCacheMiss {
+		s.logger.WarnContext(ctx, "album cache unavailable", "album_id", id, "error", err)
 	}
-	log.Println("⚠️ Cache miss for album ID:", id)
+	s.logger.DebugContext(ctx, "album cache miss", "album_id", id)
+	metrics.AlbumCacheRequests.WithLabelValues("miss").Inc()
 
-	log.Println("Fetching album ID", id, "from DB")
-	// 2️⃣ Cache miss → DB
-	album, err := s.repo.FindByID(id)
+	// on a miss load it from the database
+	album, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return model.Album{}, err
+		return model.Album{}, albumError(err)
 	}
 
-	// 3️⃣ Save to cache
-	data, _ := json.Marshal(album)
-	err = config.RedisClient.Set(
-		config.Ctx,
+	// and cache it for the next read
+	data, _ := json.Marshal(newCachedAlbum(album))
+	err = s.cache.Set(
+		ctx,
 		cacheKey,
 		data,
-		60*time.Minute,
-	).Err()
+		s.cacheTTL,
+	)
 	if err != nil {
-		log.Println("❌ Failed to set album in cache:", err)
-	} else {
-		log.Println("✅ Album cached with key:", cacheKey)
+		s.logger.WarnContext(ctx, "failed to cache album", "album_id", id, "error", err)
 	}
 
 	return album, nil
 }
 
 // CreateAlbum creates a new album
-func (s *AlbumService) CreateAlbum(album model.Album) (model.Album, error) {
-	return s.repo.Create(album)
+func (s *AlbumService) CreateAlbum(ctx context.Context, album model.Album) (model.Album, error) {
+	return s.repo.Create(ctx, album)
 }
 
-// UpdateAlbum updates an existing album by ID
-func (s *AlbumService) UpdateAlbum(id string, album model.Album) (model.Album, error) {
-	return s.repo.Update(id, album)
+// UpdateAlbum updates an existing album by ID if it is still at version.
+// A zero version updates whatever is stored.
+func (s *AlbumService) UpdateAlbum(ctx context.Context, id string, album model.Album, version int64) (model.Album, error) {
+	id, err := canonicalAlbumID(id)
+	if err != nil {
+		return model.Album{}, err
+	}
+	updated, err := s.repo.Update(ctx, id, album, version)
+	if err != nil {
+		return model.Album{}, s.writeError(ctx, id, err)
+	}
+	s.ClearAlbumCache(ctx, id)
+	return updated, nil
 }
 
-// DeleteAlbum deletes an album by ID
-func (s *AlbumService) DeleteAlbum(id string) error {
-	err := s.repo.Delete(id)
-	if err == nil {
-		s.ClearAlbumCache(id)
+// DeleteAlbum deletes an album by ID if it is still at version.
+// A zero version deletes whatever is stored.
+func (s *AlbumService) DeleteAlbum(ctx context.Context, id string, version int64) error {
+	id, err := canonicalAlbumID(id)
+	if err != nil {
+		return err
 	}
-	return err
+	if err := s.repo.Delete(ctx, id, version); err != nil {
+		return s.writeError(ctx, id, err)
+	}
+	s.ClearAlbumCache(ctx, id)
+	return nil
+}
+
+// PatchAlbum partially updates an album by ID if it is still at version.
+// A zero version patches whatever is stored. apply receives the stored album
+// and returns it as it should be; only the fields it changed are written.
+func (s *AlbumService) PatchAlbum(ctx context.Context, id string, version int64, apply func(model.Album) (model.Album, error)) (model.Album, error) {
+	id, err := canonicalAlbumID(id)
+	if err != nil {
+		return model.Album{}, err
+	}
+	// read past the cache: the patch must apply to the stored version
+	current, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return model.Album{}, albumError(err)
+	}
+	if version != 0 && current.Version != version {
+		return model.Album{}, s.writeError(ctx, id, repositories.ErrVersionConflict)
+	}
+
+	patched, err := apply(current)
+	if err != nil {
+		return model.Album{}, err
+	}
+	if err := checkReadOnly(current, patched); err != nil {
+		return model.Album{}, err
+	}
+
+	patch := albumChanges(current, patched)
+	if patch.Empty() {
+		return current, nil
+	}
+	// write only over the version the patch was applied to
+	updated, err := s.repo.Patch(ctx, id, patch, current.Version)
+	if err != nil {
+		return model.Album{}, s.writeError(ctx, id, err)
+	}
+	s.ClearAlbumCache(ctx, id)
+	return updated, nil
+}
 
+// checkReadOnly rejects a patch that changed a field the server owns.
+func checkReadOnly(from, to model.Album) error {
+	var fields []apperr.FieldError
+	if to.ID != from.ID {
+		fields = append(fields, apperr.FieldError{Field: "id", Message: "is read-only"})
+	}
+	if !to.CreatedAt.Equal(from.CreatedAt) {
+		fields = append(fields, apperr.FieldError{Field: "created_at", Message: "is read-only"})
+	}
+	if !to.UpdatedAt.Equal(from.UpdatedAt) {
+		fields = append(fields, apperr.FieldError{Field: "updated_at", Message: "is read-only"})
+	}
+	if len(fields) > 0 {
+		return apperr.Validation("patch changes read-only fields", fields...)
+	}
+	return nil
 }
 
-// PatchAlbum partially updates an album by ID
-func (s *AlbumService) PatchAlbum(id string, album model.Album) (model.Album, error) {
-	return s.repo.Patch(id, album)
+// albumChanges lists the fields that differ between from and to.
+func albumChanges(from, to model.Album) model.AlbumPatch {
+	var patch model.AlbumPatch
+	if to.Title != from.Title {
+		patch.Title = &to.Title
+	}
+	if to.Artist != from.Artist {
+		patch.Artist = &to.Artist
+	}
+	if to.Price != from.Price {
+		patch.Price = &to.Price
+	}
+	return patch
+}
+
+// writeError is albumError for writes. A version conflict may mean the
+// client read a stale cached copy, so that copy is dropped too.
+func (s *AlbumService) writeError(ctx context.Context, id string, err error) error {
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		s.ClearAlbumCache(ctx, id)
+	}
+	return albumError(err)
+}
+
+// albumError turns a missing row into a not-found error and a version
+// conflict into a failed precondition for the client.
+func albumError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return apperr.NotFound("album not found")
+	case errors.Is(err, repositories.ErrVersionConflict):
+		return apperr.PreconditionFailed("album has changed since it was read; fetch it again and retry")
+	}
+	return err
 }
 
-func (s *AlbumService) ClearAlbumCache(id string) {
-	cacheKey := "album:" + id
-	err := config.RedisClient.Del(config.Ctx, cacheKey).Err()
+// ClearAlbumCache drops the cached copy of an album so the next read hits the
+// database. id must be canonical, see canonicalAlbumID.
+func (s *AlbumService) ClearAlbumCache(ctx context.Context, id string) {
+	cacheKey := albumCacheKey(id)
+	err := s.cache.Delete(ctx, cacheKey)
 	if err != nil {
-		log.Println("❌ Failed to clear album cache for ID", id, ":", err)
-	} else {
-		log.Println("✅ Cleared album cache for ID:", id)
+		s.logger.WarnContext(ctx, "failed to clear album cache", "album_id", id, "error", err)
 	}
 }
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"example/go-web-gin/apperr"
+	"example/go-web-gin/cache"
+	"example/go-web-gin/model"
+	"example/go-web-gin/repositories"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAlbums is an in-memory repositories.AlbumRepository that counts
+// FindByID calls, so tests can tell cache hits from database reads.
+type fakeAlbums struct {
+	mu     sync.Mutex
+	albums map[string]model.Album
+	nextID int
+	reads  int
+}
+
+func newFakeAlbums() *fakeAlbums {
+	return &fakeAlbums{albums: map[string]model.Album{}}
+}
+
+// rowKey matches ids the way the databases do, "02" finding album 2.
+func rowKey(id string) string {
+	n, _ := strconv.Atoi(id)
+	return strconv.Itoa(n)
+}
+
+func (f *fakeAlbums) FindAll(ctx context.Context, q model.AlbumQuery) (model.AlbumPage, error) {
+	return model.AlbumPage{}, errors.New("not implemented")
+}
+
+func (f *fakeAlbums) Search(ctx context.Context, q string, limit int) ([]model.AlbumSearchResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeAlbums) FindByID(ctx context.Context, id string) (model.Album, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reads++
+	album, ok := f.albums[rowKey(id)]
+	if !ok {
+		return model.Album{}, sql.ErrNoRows
+	}
+	return album, nil
+}
+
+func (f *fakeAlbums) Create(ctx context.Context, album model.Album) (model.Album, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	album.ID = strconv.Itoa(f.nextID)
+	album.Version = 1
+	album.CreatedAt = time.Now()
+	album.UpdatedAt = album.CreatedAt
+	f.albums[album.ID] = album
+	return album, nil
+}
+
+// write applies change to the stored album if it is at version, as the SQL
+// repository does.
+func (f *fakeAlbums) write(id string, version int64, change func(*model.Album)) (model.Album, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id = rowKey(id)
+	album, ok := f.albums[id]
+	if !ok {
+		return model.Album{}, sql.ErrNoRows
+	}
+	if version != 0 && album.Version != version {
+		return model.Album{}, repositories.ErrVersionConflict
+	}
+	change(&album)
+	album.Version++
+	album.UpdatedAt = time.Now()
+	f.albums[id] = album
+	return album, nil
+}
+
+func (f *fakeAlbums) Update(ctx context.Context, id string, album model.Album, version int64) (model.Album, error) {
+	return f.write(id, version, func(a *model.Album) {
+		a.Title, a.Artist, a.Price = album.Title, album.Artist, album.Price
+	})
+}
+
+func (f *fakeAlbums) Patch(ctx context.Context, id string, patch model.AlbumPatch, version int64) (model.Album, error) {
+	return f.write(id, version, func(a *model.Album) {
+		if patch.Title != nil {
+			a.Title = *patch.Title
+		}
+		if patch.Artist != nil {
+			a.Artist = *patch.Artist
+		}
+		if patch.Price != nil {
+			a.Price = *patch.Price
+		}
+	})
+}
+
+func (f *fakeAlbums) Delete(ctx context.Context, id string, version int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id = rowKey(id)
+	album, ok := f.albums[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if version != 0 && album.Version != version {
+		return repositories.ErrVersionConflict
+	}
+	delete(f.albums, id)
+	return nil
+}
+
+func newTestAlbumService(t *testing.T) (*AlbumService, *fakeAlbums, cache.Cache) {
+	t.Helper()
+	repo := newFakeAlbums()
+	albumCache := cache.NewMemory(0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewAlbumService(repo, albumCache, time.Hour, logger), repo, albumCache
+}
+
+// seedCached creates an album and reads it twice by its id with prefix
+// prepended, checking the second read is served from the cache.
+func seedCached(t *testing.T, ctx context.Context, s *AlbumService, repo *fakeAlbums, prefix string) model.Album {
+	t.Helper()
+	album, err := s.CreateAlbum(ctx, model.Album{Title: "Blue Train", Artist: "John Coltrane", Price: 56.99})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := s.GetAlbumByID(ctx, prefix+album.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if repo.reads != 1 {
+		t.Fatalf("repository read %d times, want 1: second read should hit the cache", repo.reads)
+	}
+	return album
+}
+
+func TestReadAfterWriteIsFresh(t *testing.T) {
+	title := "Giant Steps"
+
+	tests := []struct {
+		name string
+		// prefix is prepended to the id in reads, which the database
+		// ignores like it ignores the zero in "/albums/02"
+		prefix string
+		write  func(ctx context.Context, s *AlbumService, album model.Album) error
+		want   string
+	}{
+		{
+			name: "update",
+			write: func(ctx context.Context, s *AlbumService, album model.Album) error {
+				album.Title = title
+				_, err := s.UpdateAlbum(ctx, album.ID, album, album.Version)
+				return err
+			},
+			want: title,
+		},
+		{
+			name: "patch",
+			write: func(ctx context.Context, s *AlbumService, album model.Album) error {
+				_, err := s.PatchAlbum(ctx, album.ID, album.Version, func(a model.Album) (model.Album, error) {
+					a.Title = title
+					return a, nil
+				})
+				return err
+			},
+			want: title,
+		},
+		{
+			name:   "update read as 0id",
+			prefix: "0",
+			write: func(ctx context.Context, s *AlbumService, album model.Album) error {
+				album.Title = title
+				_, err := s.UpdateAlbum(ctx, album.ID, album, album.Version)
+				return err
+			},
+			want: title,
+		},
+		{
+			name:   "delete read as 0id",
+			prefix: "0",
+			write: func(ctx context.Context, s *AlbumService, album model.Album) error {
+				return s.DeleteAlbum(ctx, album.ID, album.Version)
+			},
+		},
+		{
+			name: "delete",
+			write: func(ctx context.Context, s *AlbumService, album model.Album) error {
+				return s.DeleteAlbum(ctx, album.ID, album.Version)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, repo, _ := newTestAlbumService(t)
+			album := seedCached(t, ctx, s, repo, tt.prefix)
+
+			if err := tt.write(ctx, s, album); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.GetAlbumByID(ctx, tt.prefix+album.ID)
+			if tt.want == "" {
+				if e, ok := apperr.As(err); !ok || e.Kind != apperr.KindNotFound {
+					t.Fatalf("read after delete: got %+v, %v; want not found", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Title != tt.want {
+				t.Errorf("read after %s: title %q, want %q", tt.name, got.Title, tt.want)
+			}
+			if got.Version != album.Version+1 {
+				t.Errorf("read after %s: version %d, want %d", tt.name, got.Version, album.Version+1)
+			}
+		})
+	}
+}
+
+func TestVersionConflictClearsCache(t *testing.T) {
+	ctx := context.Background()
+	s, repo, albumCache := newTestAlbumService(t)
+	album := seedCached(t, ctx, s, repo, "")
+
+	// another replica changes the album, leaving this cache stale
+	if _, err := repo.Update(ctx, album.ID, model.Album{Title: "Changed", Artist: album.Artist, Price: album.Price}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := s.UpdateAlbum(ctx, album.ID, model.Album{Title: "Mine", Artist: album.Artist, Price: album.Price}, album.Version)
+	if e, ok := apperr.As(err); !ok || e.Kind != apperr.KindPreconditionFailed {
+		t.Fatalf("update with stale version: got %v, want precondition failed", err)
+	}
+
+	if _, err := albumCache.Get(ctx, albumCacheKey(album.ID)); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Fatalf("cache entry after conflict: got %v, want a miss", err)
+	}
+	got, err := s.GetAlbumByID(ctx, album.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Changed" || got.Version != album.Version+1 {
+		t.Errorf("read after conflict: got %q at version %d, want %q at version %d", got.Title, got.Version, "Changed", album.Version+1)
+	}
+}
@@ -1,41 +1,166 @@
 package service
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"example/go-web-gin/apperr"
+	"example/go-web-gin/cache"
+	"example/go-web-gin/metrics"
+	"example/go-web-gin/model"
+	"example/go-web-gin/repositories"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrInvalidCredentials is returned when the email or password does not match.
+var ErrInvalidCredentials = apperr.Unauthorized("invalid credentials")
+
 type AuthService struct {
 	jwtSecret []byte
+	users     repositories.UserRepository
+	// tokens holds refresh token state and the access token denylist
+	tokens cache.Cache
+	// dummyHash is compared against when the email is unknown so a failed
+	// login takes the same time whether or not the account exists
+	dummyHash []byte
+	ttls      TokenTTLs
+	logger    *slog.Logger
 }
 
-var (
-	demoEmail    = "sithuwin@example.com"
-	demoPassword = "password123"
-	demoUserId   = "1"
-)
+// TokenTTLs sets how long issued tokens stay valid.
+type TokenTTLs struct {
+	Access  time.Duration
+	Refresh time.Duration
+}
 
-func NewAuthService(jwtSecret []byte) *AuthService {
-	return &AuthService{jwtSecret: jwtSecret}
+func NewAuthService(jwtSecret []byte, ttls TokenTTLs, users repositories.UserRepository, tokens cache.Cache, logger *slog.Logger) *AuthService {
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+	return &AuthService{jwtSecret: jwtSecret, ttls: ttls, users: users, tokens: tokens, dummyHash: dummyHash, logger: logger}
+}
+
+func init() {
+	// jwt rounds iat down to whole seconds by default, which would put a
+	// token issued just after a revoke-all in the same second as it
+	jwt.TimePrecision = time.Microsecond
 }
 
 type Claims struct {
-	UserId string `json:"user_id"`
+	UserId string     `json:"user_id"`
+	Role   model.Role `json:"role"`
 	jwt.RegisteredClaims
 }
 
-func (s *AuthService) Login(email, password string) (string, error) {
-	if email != demoEmail || password != demoPassword {
-		return "", errors.New("invalid credentials")
+// Register creates a new account with a bcrypt-hashed password
+func (s *AuthService) Register(ctx context.Context, req model.RegisterRequest) (model.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return model.User{}, err
 	}
 
+	user, err := s.users.Create(ctx, model.User{
+		Email:        normalizeEmail(req.Email),
+		Name:         strings.TrimSpace(req.Name),
+		Role:         model.RoleViewer,
+		PasswordHash: string(hash),
+	})
+	if errors.Is(err, repositories.ErrEmailTaken) {
+		return model.User{}, apperr.Conflict("email already registered")
+	}
+	return user, err
+}
+
+// Login checks the credentials and starts a new refresh token family
+func (s *AuthService) Login(ctx context.Context, email, password string) (model.TokenPair, error) {
+	tokens, err := s.login(ctx, email, password)
+	switch {
+	case err == nil:
+		metrics.LoginAttempts.WithLabelValues("success").Inc()
+	case errors.Is(err, ErrInvalidCredentials):
+		metrics.LoginAttempts.WithLabelValues("failure").Inc()
+	default:
+		metrics.LoginAttempts.WithLabelValues("error").Inc()
+	}
+	return tokens, err
+}
+
+func (s *AuthService) login(ctx context.Context, email, password string) (model.TokenPair, error) {
+	user, err := s.users.FindByEmail(ctx, normalizeEmail(email))
+	if err == sql.ErrNoRows {
+		bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return model.TokenPair{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return model.TokenPair{}, ErrInvalidCredentials
+	}
+
+	familyID, err := randomToken()
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+	return s.issueTokenPair(ctx, user, familyID)
+}
+
+// Refresh rotates a refresh token, returning a new access and refresh token
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (model.TokenPair, error) {
+	record, err := s.rotateRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	// reload the user so the new access token carries their current role;
+	// the account may also have been deleted since the token was issued
+	user, err := s.users.FindByID(ctx, record.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.TokenPair{}, ErrInvalidRefreshToken
+		}
+		return model.TokenPair{}, err
+	}
+
+	return s.issueTokenPair(ctx, user, record.FamilyID)
+}
+
+func (s *AuthService) issueTokenPair(ctx context.Context, user model.User, familyID string) (model.TokenPair, error) {
+	accessToken, err := s.signAccessToken(user)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	refreshToken, err := s.issueRefreshToken(ctx, user.ID, familyID)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	return model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.ttls.Access.Seconds()),
+	}, nil
+}
+
+func (s *AuthService) signAccessToken(user model.User) (string, error) {
 	// 3️⃣ Create token
+	jti, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
 	claims := Claims{
-		UserId: demoUserId,
+		UserId: user.ID,
+		Role:   user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.ttls.Access)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -43,14 +168,11 @@ func (s *AuthService) Login(email, password string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// 4️⃣ Sign token with secret
-	signedToken, err := token.SignedString([]byte(s.jwtSecret))
-	if err != nil {
-		return "", err
-	}
-	return signedToken, nil
+	return token.SignedString(s.jwtSecret)
 }
 
-func (s *AuthService) ParseToken(tokenString string) (string, error) {
+// ParseToken verifies the signature and expiry of an access token and returns its claims
+func (s *AuthService) ParseToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(t *jwt.Token) (any, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
@@ -59,9 +181,12 @@ func (s *AuthService) ParseToken(tokenString string) (string, error) {
 	})
 
 	if err != nil || !token.Valid {
-		return "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
-	claims := token.Claims.(*Claims)
-	return claims.UserId, nil
+	return token.Claims.(*Claims), nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
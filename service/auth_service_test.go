@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"example/go-web-gin/cache"
+	"example/go-web-gin/model"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeUsers is an in-memory repositories.UserRepository.
+type fakeUsers struct {
+	mu    sync.Mutex
+	users []model.User
+}
+
+func (f *fakeUsers) Create(ctx context.Context, user model.User) (model.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user.ID = strconv.Itoa(len(f.users) + 1)
+	f.users = append(f.users, user)
+	return user, nil
+}
+
+func (f *fakeUsers) FindByEmail(ctx context.Context, email string) (model.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return model.User{}, sql.ErrNoRows
+}
+
+func (f *fakeUsers) FindByID(ctx context.Context, id string) (model.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return model.User{}, sql.ErrNoRows
+}
+
+func TestRevokeAllKeepsLaterLoginValid(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ttls := TokenTTLs{Access: time.Hour, Refresh: time.Hour}
+	s := NewAuthService([]byte("test-secret-test-secret-test-secret"), ttls, &fakeUsers{}, cache.NewMemory(0), logger)
+
+	user, err := s.Register(ctx, model.RegisterRequest{Email: "jane@example.com", Name: "Jane", Password: "password123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := s.Login(ctx, "jane@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RevokeAllForUser(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	// logging back in straight away lands in the same second as the revoke
+	after, err := s.Login(ctx, "jane@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		tokens  model.TokenPair
+		revoked bool
+	}{
+		{"issued before the revoke", before, true},
+		{"issued after the revoke", after, false},
+	} {
+		claims, err := s.ParseToken(tt.tokens.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		revoked, err := s.IsRevoked(ctx, claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revoked != tt.revoked {
+			t.Errorf("access token %s: revoked %t, want %t", tt.name, revoked, tt.revoked)
+		}
+
+		_, err = s.Refresh(ctx, tt.tokens.RefreshToken)
+		if refreshed := err == nil; refreshed == tt.revoked {
+			t.Errorf("refresh token %s: got error %v, want revoked %t", tt.name, err, tt.revoked)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"example/go-web-gin/apperr"
+	"example/go-web-gin/cache"
+	"time"
+)
+
+// Refresh tokens are opaque random strings. The token store keeps, per token hash, the
+// user and the "family" the token belongs to. Every refresh marks the
+// presented token as used and issues a new one in the same family; presenting
+// a used token again means it was stolen, so the whole family is revoked.
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens.
+	ErrInvalidRefreshToken = apperr.Unauthorized("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again.
+	// Clients see the same detail as for an invalid token.
+	ErrRefreshTokenReused = &apperr.Error{
+		Kind:   apperr.KindUnauthorized,
+		Detail: "invalid refresh token",
+		Err:    errors.New("refresh token reuse detected"),
+	}
+)
+
+type refreshTokenRecord struct {
+	UserID   string    `json:"user_id"`
+	FamilyID string    `json:"family_id"`
+	IssuedAt time.Time `json:"iat"`
+}
+
+func refreshTokenKey(hash string) string    { return "refresh:" + hash }
+func refreshUsedKey(hash string) string     { return "refresh_used:" + hash }
+func refreshFamilyKey(family string) string { return "refresh_family_revoked:" + family }
+
+// issueRefreshToken creates a new refresh token in the given family and stores it
+func (s *AuthService) issueRefreshToken(ctx context.Context, userID, familyID string) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	data, _ := json.Marshal(refreshTokenRecord{UserID: userID, FamilyID: familyID, IssuedAt: time.Now()})
+	err = s.tokens.Set(ctx, refreshTokenKey(hashToken(token)), data, s.ttls.Refresh)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// lookupRefreshToken returns the stored record for a refresh token that is
+// still valid, without consuming it
+func (s *AuthService) lookupRefreshToken(ctx context.Context, token string) (refreshTokenRecord, error) {
+	var record refreshTokenRecord
+	data, err := s.tokens.Get(ctx, refreshTokenKey(hashToken(token)))
+	if err == cache.ErrCacheMiss {
+		return record, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return record, err
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, ErrInvalidRefreshToken
+	}
+
+	revoked, err := s.tokens.Exists(ctx, refreshFamilyKey(record.FamilyID))
+	if err != nil {
+		return record, err
+	}
+	if revoked {
+		return record, ErrInvalidRefreshToken
+	}
+
+	revokedBefore, err := s.userRevokedBefore(ctx, record.UserID)
+	if err != nil {
+		return record, err
+	}
+	if record.IssuedAt.Before(revokedBefore) {
+		return record, ErrInvalidRefreshToken
+	}
+
+	return record, nil
+}
+
+// rotateRefreshToken consumes a refresh token and returns the record it was issued for
+func (s *AuthService) rotateRefreshToken(ctx context.Context, token string) (refreshTokenRecord, error) {
+	hash := hashToken(token)
+
+	record, err := s.lookupRefreshToken(ctx, token)
+	if err != nil {
+		return record, err
+	}
+
+	// SETNX makes rotation atomic: only the first caller may use the token
+	first, err := s.tokens.SetNX(ctx, refreshUsedKey(hash), []byte("1"), s.ttls.Refresh)
+	if err != nil {
+		return record, err
+	}
+	if !first {
+		s.logger.WarnContext(ctx, "refresh token reuse detected, revoking family",
+			"family_id", record.FamilyID,
+			"user_id", record.UserID,
+		)
+		if err := s.revokeRefreshFamily(ctx, record.FamilyID); err != nil {
+			return record, err
+		}
+		return record, ErrRefreshTokenReused
+	}
+
+	return record, nil
+}
+
+func (s *AuthService) revokeRefreshFamily(ctx context.Context, familyID string) error {
+	return s.tokens.Set(ctx, refreshFamilyKey(familyID), []byte("1"), s.ttls.Refresh)
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken keeps raw refresh tokens out of Redis
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}